@@ -0,0 +1,261 @@
+// Package appdir : Application directories (configuration, cache, data, state, runtime and log)
+//
+// These codes are licensed under CC0.
+// http://creativecommons.org/publicdomain/zero/1.0/
+package appdir
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/goark/gocli/internal/envdir"
+)
+
+// Errors of appdir package
+var (
+	ErrInvalidName = errors.New("invalid application name")
+	ErrNoDir       = errors.New("no directory for application")
+	ErrInsecureDir = errors.New("insecure directory for application")
+)
+
+// Kind is kind of application directory
+type Kind int
+
+const (
+	Config  Kind = iota + 1 // $XDG_CONFIG_HOME/<app>
+	Cache                   // $XDG_CACHE_HOME/<app>
+	Data                    // $XDG_DATA_HOME/<app>
+	State                   // $XDG_STATE_HOME/<app>
+	Runtime                 // $XDG_RUNTIME_DIR/<app>
+	Log                     // $XDG_STATE_HOME/<app>/log
+)
+
+var kindMap = map[Kind]string{
+	Config:  "config",
+	Cache:   "cache",
+	Data:    "data",
+	State:   "state",
+	Runtime: "runtime",
+	Log:     "log",
+}
+
+// Kinds returns all kinds of application directory
+func Kinds() []Kind {
+	return []Kind{Config, Cache, Data, State, Runtime, Log}
+}
+
+// String is Stringer method
+func (k Kind) String() string {
+	if s, ok := kindMap[k]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+// App is directories of application
+type App struct {
+	name      string
+	envPrefix string
+	ensure    bool
+}
+
+// OptFunc is self-referential function for functional options pattern
+type OptFunc func(*App)
+
+// New returns a new App instance
+func New(name string, opts ...OptFunc) (*App, error) {
+	if len(name) == 0 || strings.Contains(filepath.ToSlash(name), "/") || name == "." || name == ".." {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	a := &App{name: name, envPrefix: envdir.Prefix(name)}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a, nil
+}
+
+// WithEnsureDir returns function for setting whether directories are created (with permission 0700) when they are returned
+func WithEnsureDir(ensure bool) OptFunc {
+	return func(a *App) {
+		a.ensure = ensure
+	}
+}
+
+// WithEnvPrefix returns function for setting prefix of environment variables overriding directories
+// (config.EnvPrefix(name) by default). For example, MYAPP_CONFIG_DIR overrides configuration directory.
+// config.Dir and cache.Dir honor the variables of the default prefix only.
+func WithEnvPrefix(prefix string) OptFunc {
+	return func(a *App) {
+		a.envPrefix = prefix
+	}
+}
+
+// Name returns application name
+func (a *App) Name() string {
+	return a.name
+}
+
+// EnvName returns name of environment variable overriding directory of kind (e.g. MYAPP_CONFIG_DIR)
+func (a *App) EnvName(kind Kind) string {
+	return envdir.Name(a.envPrefix, kind.String())
+}
+
+// Config returns configuration directory
+func (a *App) Config() (string, error) {
+	return a.Dir(Config)
+}
+
+// Cache returns cache directory
+func (a *App) Cache() (string, error) {
+	return a.Dir(Cache)
+}
+
+// Data returns data directory
+func (a *App) Data() (string, error) {
+	return a.Dir(Data)
+}
+
+// State returns state directory (e.g. history, logs)
+func (a *App) State() (string, error) {
+	return a.Dir(State)
+}
+
+// Runtime returns runtime directory (e.g. sockets, pid files)
+func (a *App) Runtime() (string, error) {
+	return a.Dir(Runtime)
+}
+
+// Log returns log directory
+func (a *App) Log() (string, error) {
+	return a.Dir(Log)
+}
+
+// Dir returns directory of kind. Environment variable EnvName(kind) overrides it.
+// The directory is created if WithEnsureDir option is set.
+func (a *App) Dir(kind Kind) (string, error) {
+	dir := os.Getenv(a.EnvName(kind))
+	if len(dir) == 0 {
+		var err error
+		if dir, err = a.defaultDir(kind); err != nil {
+			return "", err
+		}
+	}
+	if a.ensure {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// Path returns path of file in directory of kind
+func (a *App) Path(kind Kind, fileName string) (string, error) {
+	if len(fileName) == 0 || strings.Contains(filepath.ToSlash(fileName), "/") {
+		return "", fmt.Errorf("%w: file %q", ErrInvalidName, fileName)
+	}
+	dir, err := a.Dir(kind)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+func (a *App) defaultDir(kind Kind) (string, error) {
+	var base string
+	var err error
+	switch kind {
+	case Config:
+		base, err = os.UserConfigDir()
+	case Cache:
+		base, err = os.UserCacheDir()
+	case Data:
+		base, err = userDir("XDG_DATA_HOME", ".local/share", "LocalAppData")
+	case State, Log:
+		base, err = userDir("XDG_STATE_HOME", ".local/state", "LocalAppData")
+		if err == nil && kind == Log {
+			return logDir(base, a.name)
+		}
+	case Runtime:
+		return runtimeDir(a.name)
+	default:
+		return "", fmt.Errorf("%w: kind %d", ErrNoDir, int(kind))
+	}
+	if err != nil || len(base) == 0 {
+		return "", fmt.Errorf("%w: %s: %v", ErrNoDir, kind, err)
+	}
+	return filepath.Join(base, a.name), nil
+}
+
+// userDir returns base directory from XDG environment variable env (or $HOME/fallback) on Unix,
+// ~/Library/Application Support on macOS, and environment variable winEnv on Windows.
+func userDir(env, fallback, winEnv string) (string, error) {
+	switch runtime.GOOS {
+	case "windows":
+		if dir := os.Getenv(winEnv); len(dir) > 0 {
+			return dir, nil
+		}
+		return "", fmt.Errorf("%%%s%% is not defined", winEnv)
+	case "darwin", "ios":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, "Library", "Application Support"), nil
+	}
+	if dir := os.Getenv(env); filepath.IsAbs(dir) { // relative path is invalid in XDG Base Directory
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, filepath.FromSlash(fallback)), nil
+}
+
+// logDir returns log directory: ~/Library/Logs/<app> on macOS, <state>/<app>/Logs on Windows, and <state>/<app>/log on others
+func logDir(stateBase, name string) (string, error) {
+	switch runtime.GOOS {
+	case "windows":
+		return filepath.Join(stateBase, name, "Logs"), nil
+	case "darwin", "ios":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("%w: %s: %v", ErrNoDir, Log, err)
+		}
+		return filepath.Join(home, "Library", "Logs", name), nil
+	}
+	return filepath.Join(stateBase, name, "log"), nil
+}
+
+// runtimeDir returns $XDG_RUNTIME_DIR/<app>, or per-user directory in temporary directory if it is not defined.
+// The latter is shared with other users, so it is created (regardless of WithEnsureDir option) and
+// must be a directory owned by current user with permission 0700.
+func runtimeDir(name string) (string, error) {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); filepath.IsAbs(dir) && runtime.GOOS != "windows" {
+		return filepath.Join(dir, name), nil
+	}
+	dir := filepath.Join(os.TempDir(), name)
+	if uid := os.Getuid(); uid >= 0 {
+		dir = filepath.Join(os.TempDir(), name+"-"+strconv.Itoa(uid))
+	}
+	if err := os.Mkdir(dir, 0700); err != nil && !errors.Is(err, fs.ErrExist) {
+		return "", err
+	}
+	fi, err := os.Lstat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !fi.IsDir() { // including symbolic link
+		return "", fmt.Errorf("%w: %s is not a directory", ErrInsecureDir, dir)
+	}
+	if err := checkPrivate(fi); err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrInsecureDir, dir, err)
+	}
+	return dir, nil
+}
@@ -0,0 +1,184 @@
+package appdir_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/goark/gocli/appdir"
+	"github.com/goark/gocli/cache"
+	"github.com/goark/gocli/config"
+)
+
+func TestNewError(t *testing.T) {
+	for _, name := range []string{"", "foo/bar", ".."} {
+		if _, err := appdir.New(name); !errors.Is(err, appdir.ErrInvalidName) {
+			t.Errorf("appdir.New(\"%v\") is \"%v\", want \"%v\".", name, err, appdir.ErrInvalidName)
+		}
+	}
+}
+
+func TestKindString(t *testing.T) {
+	testCases := []struct {
+		kind appdir.Kind
+		str  string
+	}{
+		{kind: appdir.Config, str: "config"},
+		{kind: appdir.Runtime, str: "runtime"},
+		{kind: appdir.Log, str: "log"},
+		{kind: appdir.Kind(0), str: "unknown"},
+	}
+	for _, tc := range testCases {
+		if tc.kind.String() != tc.str {
+			t.Errorf("Kind.String() = \"%v\", want \"%v\".", tc.kind, tc.str)
+		}
+	}
+}
+
+func TestEnvOverride(t *testing.T) {
+	app, err := appdir.New("my-app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kind := range appdir.Kinds() {
+		dir := filepath.Join(t.TempDir(), kind.String())
+		name := app.EnvName(kind)
+		t.Setenv(name, dir)
+		if got, err := app.Dir(kind); err != nil || got != dir {
+			t.Errorf("App.Dir(%v) with %v = \"%v\" (%v), want \"%v\".", kind, name, got, err, dir)
+		}
+	}
+	if name := app.EnvName(appdir.Config); name != "MY_APP_CONFIG_DIR" {
+		t.Errorf("App.EnvName() = \"%v\", want \"MY_APP_CONFIG_DIR\".", name)
+	}
+	other, _ := appdir.New("my-app", appdir.WithEnvPrefix("TOOL_"))
+	if name := other.EnvName(appdir.Data); name != "TOOL_DATA_DIR" {
+		t.Errorf("App.EnvName() = \"%v\", want \"TOOL_DATA_DIR\".", name)
+	}
+}
+
+func TestXDGDirs(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" || runtime.GOOS == "ios" || runtime.GOOS == "plan9" {
+		t.Skipf("XDG Base Directory is not used on %s", runtime.GOOS)
+	}
+	root := t.TempDir()
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_DATA_HOME", "XDG_STATE_HOME", "XDG_RUNTIME_DIR"} {
+		t.Setenv(env, filepath.Join(root, env))
+	}
+	app, err := appdir.New("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		dir  func() (string, error)
+		want string
+	}{
+		{dir: app.Config, want: filepath.Join(root, "XDG_CONFIG_HOME", "app")},
+		{dir: app.Cache, want: filepath.Join(root, "XDG_CACHE_HOME", "app")},
+		{dir: app.Data, want: filepath.Join(root, "XDG_DATA_HOME", "app")},
+		{dir: app.State, want: filepath.Join(root, "XDG_STATE_HOME", "app")},
+		{dir: app.Runtime, want: filepath.Join(root, "XDG_RUNTIME_DIR", "app")},
+		{dir: app.Log, want: filepath.Join(root, "XDG_STATE_HOME", "app", "log")},
+	}
+	for _, tc := range testCases {
+		if got, err := tc.dir(); err != nil || got != tc.want {
+			t.Errorf("App directory = \"%v\" (%v), want \"%v\".", got, err, tc.want)
+		}
+	}
+
+	t.Setenv("XDG_DATA_HOME", "relative/path") // ignored
+	if got, err := app.Data(); err != nil || got == filepath.Join("relative", "path", "app") {
+		t.Errorf("App.Data() with relative XDG_DATA_HOME = \"%v\" (%v), want default.", got, err)
+	}
+}
+
+func TestEnsureDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state", "app")
+	t.Setenv("APP_STATE_DIR", dir)
+	app, err := appdir.New("app", appdir.WithEnsureDir(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := app.Path(appdir.State, "history")
+	if err != nil {
+		t.Fatalf("App.Path() is \"%v\", want nil error.", err)
+	}
+	if path != filepath.Join(dir, "history") {
+		t.Errorf("App.Path() = \"%v\", want \"%v\".", path, filepath.Join(dir, "history"))
+	}
+	fi, err := os.Stat(dir)
+	if err != nil || !fi.IsDir() {
+		t.Fatalf("directory is not created: %v", err)
+	}
+	if runtime.GOOS != "windows" && fi.Mode().Perm() != 0700 {
+		t.Errorf("permission of directory = %v, want 0700.", fi.Mode().Perm())
+	}
+	if _, err := app.Path(appdir.State, "../history"); !errors.Is(err, appdir.ErrInvalidName) {
+		t.Errorf("App.Path() is \"%v\", want \"%v\".", err, appdir.ErrInvalidName)
+	}
+}
+
+func TestRuntimeFallback(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skipf("permission is not checked on %s", runtime.GOOS)
+	}
+	root := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("TMPDIR", root)
+	app, err := appdir.New("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(root, "app-"+strconv.Itoa(os.Getuid()))
+	if got, err := app.Runtime(); err != nil || got != want {
+		t.Fatalf("App.Runtime() = \"%v\" (%v), want \"%v\".", got, err, want)
+	}
+	if fi, err := os.Lstat(want); err != nil || !fi.IsDir() || fi.Mode().Perm() != 0700 {
+		t.Errorf("runtime directory = %v (%v), want directory with permission 0700.", fi, err)
+	}
+
+	if err := os.Chmod(want, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Runtime(); !errors.Is(err, appdir.ErrInsecureDir) {
+		t.Errorf("App.Runtime() of directory with permission 0755 is \"%v\", want \"%v\".", err, appdir.ErrInsecureDir)
+	}
+	if err := os.Remove(want); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(t.TempDir(), want); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Runtime(); !errors.Is(err, appdir.ErrInsecureDir) {
+		t.Errorf("App.Runtime() of symbolic link is \"%v\", want \"%v\".", err, appdir.ErrInsecureDir)
+	}
+}
+
+func TestEnvOverrideShared(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("MY_APP_CONFIG_DIR", filepath.Join(root, "config"))
+	t.Setenv("MY_APP_CACHE_DIR", filepath.Join(root, "cache"))
+	app, err := appdir.New("my-app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		dir  func() (string, error)
+		got  string
+		want string
+	}{
+		{dir: app.Config, got: config.Dir("my-app"), want: filepath.Join(root, "config")},
+		{dir: app.Cache, got: cache.Dir("my-app"), want: filepath.Join(root, "cache")},
+	}
+	for _, tc := range testCases {
+		if dir, err := tc.dir(); err != nil || dir != tc.want || tc.got != tc.want {
+			t.Errorf("App directory = \"%v\" (%v) and package directory = \"%v\", want \"%v\".", dir, err, tc.got, tc.want)
+		}
+	}
+	if path := config.Path("my-app", "config.json"); path != filepath.Join(root, "config", "config.json") {
+		t.Errorf("config.Path() = \"%v\", want in \"%v\".", path, filepath.Join(root, "config"))
+	}
+}
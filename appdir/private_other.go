@@ -0,0 +1,10 @@
+//go:build !unix
+
+package appdir
+
+import "os"
+
+// checkPrivate does nothing on platforms without Unix permissions (e.g. Windows, plan9)
+func checkPrivate(fi os.FileInfo) error {
+	return nil
+}
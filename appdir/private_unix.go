@@ -0,0 +1,20 @@
+//go:build unix
+
+package appdir
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkPrivate returns error if directory is not owned by current user or accessible by others
+func checkPrivate(fi os.FileInfo) error {
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok && int(st.Uid) != os.Getuid() {
+		return fmt.Errorf("owned by uid %d", st.Uid)
+	}
+	if perm := fi.Mode().Perm(); perm != 0700 {
+		return fmt.Errorf("permission %v", perm)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrInvalidDigest is error for malformed digest of blob
+var ErrInvalidDigest = errors.New("invalid blob digest")
+
+// blobDir is name of directory of BlobStore in cache directory
+const blobDir = "blobs"
+
+// BlobStore is content-addressable store of blobs in user cache directory.
+// Blobs are keyed by SHA-256 digest of their content, and sharded as blobs/ab/cdef... by the digest.
+type BlobStore struct {
+	dir string
+}
+
+// NewBlobStore returns a new BlobStore instance rooted at Dir(appName)/blobs.
+// Options of Store (e.g. WithDir) are available.
+func NewBlobStore(appName string, opts ...OptFunc) (*BlobStore, error) {
+	s, err := NewStore(appName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &BlobStore{dir: filepath.Join(s.dir, blobDir)}, nil
+}
+
+// Dir returns root directory of BlobStore
+func (b *BlobStore) Dir() string {
+	return b.dir
+}
+
+// Put stores content read from r, and returns its SHA-256 digest (hex-encoded).
+// The blob is written atomically; putting the same content again is no-op.
+func (b *BlobStore) Put(r io.Reader) (string, error) {
+	if err := os.MkdirAll(b.dir, 0700); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(b.dir, tmpPrefix+"*")
+	if err != nil {
+		return "", err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) //nolint:errcheck // no-op after rename
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, h), r); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	digest := hex.EncodeToString(h.Sum(nil))
+	path := b.path(digest)
+	if _, err := os.Stat(path); err == nil { // already stored
+		now := time.Now()
+		_ = os.Chtimes(path, now, now) // for Prune with MaxAge
+		return digest, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+// Get returns reader streaming blob for digest. Missing blob returns ErrNotFound.
+// Content is verified while reading: if it does not match digest, Read returns ErrBrokenEntry
+// at the end of the stream (data already read must be discarded), and the corrupted blob is evicted.
+func (b *BlobStore) Get(digest string) (io.ReadCloser, error) {
+	if err := checkDigest(digest); err != nil {
+		return nil, err
+	}
+	path := b.path(digest)
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %w", ErrNotFound, err)
+		}
+		return nil, err
+	}
+	return &blobReader{file: file, path: path, digest: digest, hash: sha256.New()}, nil
+}
+
+// Has reports whether blob for digest is stored (without verifying content)
+func (b *BlobStore) Has(digest string) bool {
+	if checkDigest(digest) != nil {
+		return false
+	}
+	_, err := os.Stat(b.path(digest))
+	return err == nil
+}
+
+// Delete removes blob for digest. Deleting missing blob is not error.
+func (b *BlobStore) Delete(digest string) error {
+	if err := checkDigest(digest); err != nil {
+		return err
+	}
+	if err := os.Remove(b.path(digest)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path returns path of blob file for digest (blobs/ab/cdef...)
+func (b *BlobStore) path(digest string) string {
+	return filepath.Join(b.dir, digest[:2], digest[2:])
+}
+
+// checkDigest checks digest is hex-encoded SHA-256 in lower case
+func checkDigest(digest string) error {
+	if len(digest) != sha256.Size*2 {
+		return fmt.Errorf("%w: %q", ErrInvalidDigest, digest)
+	}
+	for _, c := range digest {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return fmt.Errorf("%w: %q", ErrInvalidDigest, digest)
+		}
+	}
+	return nil
+}
+
+// blobReader is reader of blob file verifying its digest at the end of stream
+type blobReader struct {
+	file   *os.File
+	path   string
+	digest string
+	hash   hash.Hash
+	err    error
+}
+
+func (r *blobReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := r.file.Read(p)
+	r.hash.Write(p[:n])
+	if errors.Is(err, io.EOF) {
+		if hex.EncodeToString(r.hash.Sum(nil)) != r.digest {
+			_ = r.file.Close()
+			_ = os.Remove(r.path)
+			r.err = fmt.Errorf("%w: %s (digest mismatch)", ErrBrokenEntry, r.digest)
+			return n, r.err
+		}
+		r.err = io.EOF
+	}
+	return n, err
+}
+
+func (r *blobReader) Close() error {
+	if err := r.file.Close(); err != nil && !errors.Is(err, fs.ErrClosed) {
+		return err
+	}
+	return nil
+}
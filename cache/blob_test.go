@@ -0,0 +1,95 @@
+package cache_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goark/gocli/cache"
+)
+
+func newTestBlobStore(t *testing.T) *cache.BlobStore {
+	t.Helper()
+	b, err := cache.NewBlobStore("app", cache.WithDir(t.TempDir()))
+	if err != nil {
+		t.Fatalf("cache.NewBlobStore() is \"%v\", want nil error.", err)
+	}
+	return b
+}
+
+func TestBlobStore(t *testing.T) {
+	b := newTestBlobStore(t)
+	content := "artifact content"
+	sum := sha256.Sum256([]byte(content))
+	want := hex.EncodeToString(sum[:])
+
+	for i := 0; i < 2; i++ {
+		digest, err := b.Put(strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("BlobStore.Put() is \"%v\", want nil error.", err)
+		}
+		if digest != want {
+			t.Errorf("BlobStore.Put() = %v, want %v.", digest, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(b.Dir(), want[:2], want[2:])); err != nil {
+		t.Errorf("blob file is not sharded: %v", err)
+	}
+	if !b.Has(want) {
+		t.Errorf("BlobStore.Has() = false, want true.")
+	}
+
+	r, err := b.Get(want)
+	if err != nil {
+		t.Fatalf("BlobStore.Get() is \"%v\", want nil error.", err)
+	}
+	data, err := io.ReadAll(r)
+	_ = r.Close()
+	if err != nil || string(data) != content {
+		t.Errorf("BlobStore.Get() = \"%s\" (%v), want \"%s\".", data, err, content)
+	}
+
+	if err := b.Delete(want); err != nil {
+		t.Errorf("BlobStore.Delete() is \"%v\", want nil error.", err)
+	}
+	if _, err := b.Get(want); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("BlobStore.Get() of deleted blob is \"%v\", want \"%v\".", err, cache.ErrNotFound)
+	}
+}
+
+func TestBlobStoreCorrupted(t *testing.T) {
+	b := newTestBlobStore(t)
+	digest, err := b.Put(strings.NewReader("original"))
+	if err != nil {
+		t.Fatalf("BlobStore.Put() is \"%v\", want nil error.", err)
+	}
+	if err := os.WriteFile(filepath.Join(b.Dir(), digest[:2], digest[2:]), []byte("tampered"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := b.Get(digest)
+	if err != nil {
+		t.Fatalf("BlobStore.Get() is \"%v\", want nil error.", err)
+	}
+	_, err = io.ReadAll(r)
+	_ = r.Close()
+	if !errors.Is(err, cache.ErrBrokenEntry) {
+		t.Errorf("reading corrupted blob is \"%v\", want \"%v\".", err, cache.ErrBrokenEntry)
+	}
+	if b.Has(digest) {
+		t.Errorf("corrupted blob is not evicted.")
+	}
+}
+
+func TestBlobStoreInvalidDigest(t *testing.T) {
+	b := newTestBlobStore(t)
+	for _, digest := range []string{"", "abc", "../../etc/passwd", strings.Repeat("G", 64)} {
+		if _, err := b.Get(digest); !errors.Is(err, cache.ErrInvalidDigest) {
+			t.Errorf("BlobStore.Get(\"%v\") is \"%v\", want \"%v\".", digest, err, cache.ErrInvalidDigest)
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/goark/gocli/internal/envdir"
 )
 
 //Path returns path of user cache file
@@ -22,11 +24,15 @@ func Path(appName, fileName string) string {
 	return filepath.Join(dir, fileName)
 }
 
-//Dir returns user cache directory
+//Dir returns user cache directory.
+//Environment variable <PREFIX>CACHE_DIR (e.g. MYAPP_CACHE_DIR for "myapp") overrides it.
 func Dir(appName string) string {
 	if includeSlash(appName) {
 		return ""
 	}
+	if dir := envdir.Lookup(appName, "cache"); len(dir) > 0 {
+		return dir
+	}
 	dir, err := os.UserCacheDir()
 	if err != nil || len(dir) == 0 {
 		dir = ""
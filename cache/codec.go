@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrNoCodec is error for codec not registered
+var ErrNoCodec = errors.New("no codec for cache entry")
+
+// DefaultCodec is name of codec used by WithCompression("")
+const DefaultCodec = "gzip"
+
+// Codec is interface for compressing data of cache entries
+type Codec interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+var (
+	codecMutex sync.RWMutex
+	codecMap   = map[string]Codec{DefaultCodec: gzipCodec{}}
+)
+
+// RegisterCodec registers Codec by name (e.g. "zstd"), which is recorded in entries compressed by it.
+// gzip codec is registered by default. If c is nil, the codec for name is removed.
+func RegisterCodec(name string, c Codec) {
+	codecMutex.Lock()
+	defer codecMutex.Unlock()
+	if c == nil {
+		delete(codecMap, name)
+		return
+	}
+	codecMap[name] = c
+}
+
+// CodecFor returns Codec registered by name
+func CodecFor(name string) (Codec, bool) {
+	codecMutex.RLock()
+	defer codecMutex.RUnlock()
+	c, ok := codecMap[name]
+	return c, ok
+}
+
+// WithCompression returns function for setting codec compressing new entries (DefaultCodec if name is empty).
+// The codec is recorded per entry, so entries written with other (or no) codec stay readable.
+func WithCompression(name string) OptFunc {
+	return func(s *Store) {
+		if len(name) == 0 {
+			name = DefaultCodec
+		}
+		s.codec = name
+	}
+}
+
+// compress compresses data with codec of Store, and returns it with name of the codec
+// (empty name and data as is, if the store has no codec or compression does not reduce size).
+func (s *Store) compress(data []byte) (string, []byte, error) {
+	if len(s.codec) == 0 {
+		return "", data, nil
+	}
+	c, ok := CodecFor(s.codec)
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %s", ErrNoCodec, s.codec)
+	}
+	compressed, err := c.Compress(data)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(compressed) >= len(data) {
+		return "", data, nil
+	}
+	return s.codec, compressed, nil
+}
+
+// decompress decompresses data of entry encoded with codec name
+func decompress(name string, data []byte) ([]byte, error) {
+	if len(name) == 0 {
+		return data, nil
+	}
+	c, ok := CodecFor(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoCodec, name)
+	}
+	return c.Decompress(data)
+}
+
+// gzipCodec is Codec of gzip (compress/gzip package)
+type gzipCodec struct{}
+
+func (gzipCodec) Compress(data []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipCodec) Decompress(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close() //nolint:errcheck
+	return io.ReadAll(zr)
+}
@@ -0,0 +1,104 @@
+package cache_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/goark/gocli/cache"
+)
+
+// reverseCodec is Codec for testing
+type reverseCodec struct{}
+
+func (reverseCodec) Compress(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data)/2)
+	for i := len(data) - 1; i >= 0; i -= 2 {
+		out = append(out, data[i])
+	}
+	return out, nil
+}
+
+func (reverseCodec) Decompress(data []byte) ([]byte, error) {
+	return nil, errors.New("irreversible")
+}
+
+func TestStoreCompression(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte(strings.Repeat(`{"name":"gocli","count":1}`, 100))
+	compressed, err := cache.NewStore("app", cache.WithDir(dir), cache.WithCompression(""))
+	if err != nil {
+		t.Fatalf("cache.NewStore() is \"%v\", want nil error.", err)
+	}
+	plain, err := cache.NewStore("app", cache.WithDir(dir))
+	if err != nil {
+		t.Fatalf("cache.NewStore() is \"%v\", want nil error.", err)
+	}
+	if err := compressed.Set("compressed", data, 0); err != nil {
+		t.Fatalf("Store.Set() is \"%v\", want nil error.", err)
+	}
+	if err := compressed.Set("small", []byte("x"), 0); err != nil {
+		t.Fatalf("Store.Set() is \"%v\", want nil error.", err)
+	}
+	if err := plain.Set("plain", data, 0); err != nil {
+		t.Fatalf("Store.Set() is \"%v\", want nil error.", err)
+	}
+
+	testCases := []struct {
+		key      string
+		encoding string
+	}{
+		{key: "compressed", encoding: cache.DefaultCodec},
+		{key: "small", encoding: ""}, // not reduced by compression
+		{key: "plain", encoding: ""},
+	}
+	for _, s := range []*cache.Store{compressed, plain} { // mixed entries are readable by both
+		for _, tc := range testCases {
+			got, meta, err := s.Get(tc.key)
+			if err != nil {
+				t.Fatalf("Store.Get(\"%v\") is \"%v\", want nil error.", tc.key, err)
+			}
+			if meta.Encoding != tc.encoding || meta.Size != int64(len(got)) {
+				t.Errorf("Store.Get(\"%v\") metadata = %+v, want encoding %q and size %d.", tc.key, meta, tc.encoding, len(got))
+			}
+			if tc.key != "small" && !bytes.Equal(got, data) {
+				t.Errorf("Store.Get(\"%v\") = \"%s\", want original data.", tc.key, got)
+			}
+		}
+	}
+
+	u, err := plain.Usage()
+	if err != nil {
+		t.Fatalf("Store.Usage() is \"%v\", want nil error.", err)
+	}
+	if u.LogicalBytes != int64(2*len(data)+1) || u.Bytes >= u.LogicalBytes {
+		t.Errorf("Store.Usage() = %+v, want logical %d bytes and smaller compressed size.", u, 2*len(data)+1)
+	}
+}
+
+func TestStoreCodecRegistry(t *testing.T) {
+	if _, err := cache.NewStore("app", cache.WithDir(t.TempDir()), cache.WithCompression("unknown")); !errors.Is(err, cache.ErrNoCodec) {
+		t.Errorf("cache.NewStore() is \"%v\", want \"%v\".", err, cache.ErrNoCodec)
+	}
+
+	cache.RegisterCodec("reverse", reverseCodec{})
+	defer cache.RegisterCodec("reverse", nil)
+	if _, ok := cache.CodecFor("reverse"); !ok {
+		t.Fatalf("cache.CodecFor() = false, want true.")
+	}
+	s, err := cache.NewStore("app", cache.WithDir(t.TempDir()), cache.WithCompression("reverse"))
+	if err != nil {
+		t.Fatalf("cache.NewStore() is \"%v\", want nil error.", err)
+	}
+	if err := s.Set("key", []byte("data"), 0); err != nil {
+		t.Fatalf("Store.Set() is \"%v\", want nil error.", err)
+	}
+	if _, _, err := s.Get("key"); !errors.Is(err, cache.ErrBrokenEntry) {
+		t.Errorf("Store.Get() of undecodable entry is \"%v\", want \"%v\".", err, cache.ErrBrokenEntry)
+	}
+	cache.RegisterCodec("reverse", nil)
+	if _, _, err := s.Get("key"); !errors.Is(err, cache.ErrNoCodec) || !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("Store.Get() of unregistered codec is \"%v\", want \"%v\".", err, cache.ErrNoCodec)
+	}
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WithStaleIfError returns function for setting how long an expired entry may be served by GetOrCompute
+// when computing fresh data fails (stale-if-error; disabled if not positive).
+func WithStaleIfError(maxStale time.Duration) OptFunc {
+	return func(s *Store) {
+		s.maxStale = maxStale
+	}
+}
+
+// GetStale returns data and metadata of entry for key even if the entry is expired.
+// Use Metadata.Expired to check freshness.
+func (s *Store) GetStale(key string) ([]byte, *Metadata, error) {
+	unlock, err := s.lockEntry(key, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlock()
+	meta, data, err := s.load(key, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, meta, nil
+}
+
+// GetOrCompute returns entry for key if it is fresh. Otherwise compute is called to compute data,
+// which is stored atomically with ttl and returned.
+// Only one caller across processes computes a missing entry at a time; the others wait for it and return the stored result.
+// If compute fails and the store is created with WithStaleIfError, the expired entry is returned instead (check Metadata.Expired).
+// Waiting for lock and compute are canceled by ctx (e.g. signal.Context), and then error of ctx is returned.
+func (s *Store) GetOrCompute(ctx context.Context, key string, ttl time.Duration, compute func(context.Context) ([]byte, error), opts ...EntryOptFunc) ([]byte, *Metadata, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if unlock, err := s.lockEntryContext(ctx, key, false); err == nil {
+		data, meta, err := s.get(key)
+		unlock()
+		if err == nil || !errors.Is(err, ErrNotFound) {
+			return data, meta, err
+		}
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, nil, err
+	}
+
+	unlock, err := s.lockEntryContext(ctx, key, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlock()
+	if data, meta, err := s.get(key); err == nil { // computed by other while waiting
+		return data, meta, nil
+	}
+	data, err := compute(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		if data, meta, ok := s.stale(key); ok {
+			return data, meta, nil
+		}
+		return nil, nil, err
+	}
+	meta, err := s.set(key, data, ttl, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, meta, nil
+}
+
+// stale returns expired entry for key if it can be served under stale-if-error
+func (s *Store) stale(key string) ([]byte, *Metadata, bool) {
+	if s.maxStale <= 0 {
+		return nil, nil, false
+	}
+	meta, data, err := s.load(key, true)
+	if err != nil || meta.Expires.IsZero() || s.now().After(meta.Expires.Add(s.maxStale)) {
+		return nil, nil, false
+	}
+	return data, meta, true
+}
@@ -0,0 +1,116 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/goark/gocli/cache"
+)
+
+func TestGetOrCompute(t *testing.T) {
+	s, clock := newTestStore(t)
+	ctx := context.Background()
+	calls := 0
+	compute := func(context.Context) ([]byte, error) {
+		calls++
+		return []byte("computed"), nil
+	}
+
+	for i := 0; i < 2; i++ {
+		data, meta, err := s.GetOrCompute(ctx, "key", time.Hour, compute, cache.WithContentType("text/plain"))
+		if err != nil {
+			t.Fatalf("Store.GetOrCompute() is \"%v\", want nil error.", err)
+		}
+		if string(data) != "computed" || meta.ContentType != "text/plain" {
+			t.Errorf("Store.GetOrCompute() = \"%s\", %+v, want \"computed\".", data, meta)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("compute is called %d times, want 1.", calls)
+	}
+
+	clock.Advance(time.Hour)
+	if _, _, err := s.GetOrCompute(ctx, "key", time.Hour, compute); err != nil {
+		t.Fatalf("Store.GetOrCompute() is \"%v\", want nil error.", err)
+	}
+	if calls != 2 {
+		t.Errorf("compute is called %d times after expiry, want 2.", calls)
+	}
+}
+
+func TestGetOrComputeStaleIfError(t *testing.T) {
+	errCompute := errors.New("compute error")
+	failure := func(context.Context) ([]byte, error) { return nil, errCompute }
+
+	testCases := []struct {
+		maxStale time.Duration
+		elapsed  time.Duration
+		err      error
+	}{
+		{maxStale: 0, elapsed: 2 * time.Hour, err: errCompute},
+		{maxStale: time.Hour, elapsed: 90 * time.Minute, err: nil},
+		{maxStale: time.Hour, elapsed: 3 * time.Hour, err: errCompute},
+	}
+	for _, tc := range testCases {
+		s, clock := newTestStore(t, cache.WithStaleIfError(tc.maxStale))
+		if err := s.Set("key", []byte("stale"), time.Hour); err != nil {
+			t.Fatalf("Store.Set() is \"%v\", want nil error.", err)
+		}
+		clock.Advance(tc.elapsed)
+		data, meta, err := s.GetOrCompute(context.Background(), "key", time.Hour, failure)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("Store.GetOrCompute() (max stale %v, elapsed %v) is \"%v\", want \"%v\".", tc.maxStale, tc.elapsed, err, tc.err)
+		}
+		if err == nil && (string(data) != "stale" || !meta.Expired(clock.Now())) {
+			t.Errorf("Store.GetOrCompute() = \"%s\", %+v, want expired entry.", data, meta)
+		}
+	}
+}
+
+func TestGetOrComputeCancel(t *testing.T) {
+	s, _ := newTestStore(t, cache.WithStaleIfError(time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := s.GetOrCompute(ctx, "key", time.Hour, func(ctx context.Context) ([]byte, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		done <- err
+	}()
+	<-started
+
+	// the entry is locked while computing
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer waitCancel()
+	if _, _, err := s.GetOrCompute(waitCtx, "key", time.Hour, func(context.Context) ([]byte, error) { return []byte("x"), nil }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Store.GetOrCompute() while locked is \"%v\", want \"%v\".", err, context.DeadlineExceeded)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Store.GetOrCompute() is \"%v\", want \"%v\".", err, context.Canceled)
+	}
+	if _, _, err := s.GetOrCompute(ctx, "key", time.Hour, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Store.GetOrCompute() with canceled context is \"%v\", want \"%v\".", err, context.Canceled)
+	}
+}
+
+func TestGetStale(t *testing.T) {
+	s, clock := newTestStore(t)
+	if err := s.Set("key", []byte("data"), time.Minute); err != nil {
+		t.Fatalf("Store.Set() is \"%v\", want nil error.", err)
+	}
+	clock.Advance(time.Hour)
+	data, meta, err := s.GetStale("key")
+	if err != nil || string(data) != "data" || !meta.Expired(clock.Now()) {
+		t.Errorf("Store.GetStale() = \"%s\", %+v (%v), want expired entry.", data, meta, err)
+	}
+	if _, _, err := s.GetStale("missing"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("Store.GetStale() of missing entry is \"%v\", want \"%v\".", err, cache.ErrNotFound)
+	}
+}
@@ -0,0 +1,279 @@
+// Package httpcache : HTTP response cache transport built on cache.Store
+//
+// These codes are licensed under CC0.
+// http://creativecommons.org/publicdomain/zero/1.0/
+package httpcache
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goark/gocli/cache"
+)
+
+// HeaderFromCache is header added to responses served from cache
+const HeaderFromCache = "X-From-Cache"
+
+// varyPrefix is prefix of headers recording request headers named by Vary header in cached responses
+const varyPrefix = "X-Varied-"
+
+// Transport is http.RoundTripper caching responses of GET requests in cache.Store.
+// It honors Cache-Control, Expires, ETag, Last-Modified and Vary headers,
+// and revalidates stale responses with If-None-Match and If-Modified-Since.
+// Requests with Authorization header are not cached.
+type Transport struct {
+	store     *cache.Store
+	transport http.RoundTripper
+	offline   bool
+	now       func() time.Time
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// OptFunc is self-referential function for functional options pattern
+type OptFunc func(*Transport)
+
+// New returns a new Transport instance storing responses in store
+func New(store *cache.Store, opts ...OptFunc) *Transport {
+	t := &Transport{store: store, transport: http.DefaultTransport, now: time.Now}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// WithTransport returns function for setting underlying transport (http.DefaultTransport by default)
+func WithTransport(rt http.RoundTripper) OptFunc {
+	return func(t *Transport) {
+		if rt != nil {
+			t.transport = rt
+		}
+	}
+}
+
+// WithOffline returns function for setting offline mode:
+// cached responses are served even if stale when the network (or server) fails.
+func WithOffline(offline bool) OptFunc {
+	return func(t *Transport) {
+		t.offline = offline
+	}
+}
+
+// WithClock returns function for setting clock of Transport (for testing; use the same clock as cache.Store)
+func WithClock(now func() time.Time) OptFunc {
+	return func(t *Transport) {
+		if now != nil {
+			t.now = now
+		}
+	}
+}
+
+// Client returns http.Client with Transport
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqCC := parseCacheControl(req.Header)
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" || req.Header.Get("Authorization") != "" || reqCC.has("no-store") {
+		return t.transport.RoundTrip(req)
+	}
+	key := req.URL.String()
+	cached, meta := t.load(key, req)
+	if cached != nil && !meta.Expired(t.now()) && !reqCC.has("no-cache") && reqCC["max-age"] != "0" {
+		return cached, nil
+	}
+
+	outreq := req
+	if cached != nil { // revalidate
+		outreq = req.Clone(req.Context())
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			outreq.Header.Set("If-None-Match", etag)
+		}
+		if lm := cached.Header.Get("Last-Modified"); lm != "" {
+			outreq.Header.Set("If-Modified-Since", lm)
+		}
+	}
+	resp, err := t.transport.RoundTrip(outreq)
+	if err != nil {
+		if cached != nil && t.offline && req.Context().Err() == nil {
+			return stale(cached), nil
+		}
+		return nil, err
+	}
+	if cached != nil {
+		switch {
+		case resp.StatusCode == http.StatusNotModified:
+			_ = resp.Body.Close()
+			for _, name := range []string{"Cache-Control", "Date", "Expires", "ETag", "Last-Modified"} {
+				if v := resp.Header.Get(name); v != "" {
+					cached.Header.Set(name, v)
+				}
+			}
+			if body, err := io.ReadAll(cached.Body); err == nil {
+				_ = cached.Body.Close()
+				return t.save(key, req, cached, body), nil
+			}
+			return cached, nil
+		case resp.StatusCode >= http.StatusInternalServerError && t.offline:
+			_ = resp.Body.Close()
+			return stale(cached), nil
+		}
+		_ = cached.Body.Close()
+	}
+	if !cacheableStatus(resp.StatusCode) {
+		return resp, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	return t.save(key, req, resp, body), nil
+}
+
+// load returns cached response for key (nil if missing)
+func (t *Transport) load(key string, req *http.Request) (*http.Response, *cache.Metadata) {
+	data, meta, err := t.store.GetStale(key)
+	if err != nil {
+		return nil, nil
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), req)
+	if err != nil {
+		return nil, nil
+	}
+	for _, name := range varyNames(resp.Header) {
+		if req.Header.Get(name) != resp.Header.Get(varyPrefix+name) { // other variant
+			_ = resp.Body.Close()
+			return nil, nil
+		}
+		resp.Header.Del(varyPrefix + name)
+	}
+	resp.Header.Set(HeaderFromCache, "1")
+	return resp, meta
+}
+
+// save stores response with body (if cacheable), and returns response to be returned to client
+func (t *Transport) save(key string, req *http.Request, resp *http.Response, body []byte) *http.Response {
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.TransferEncoding = nil
+	resp.Header.Del("Content-Length")
+	if expires, ok := t.expires(resp); ok {
+		fromCache := resp.Header.Get(HeaderFromCache)
+		resp.Header.Del(HeaderFromCache)
+		stored := resp.Header.Clone()
+		for _, name := range varyNames(resp.Header) {
+			resp.Header.Set(varyPrefix+name, req.Header.Get(name))
+		}
+		buf := &bytes.Buffer{}
+		if err := resp.Write(buf); err == nil {
+			_ = t.store.Set(key, buf.Bytes(), 0,
+				cache.WithExpires(expires),
+				cache.WithContentType(resp.Header.Get("Content-Type")),
+				cache.WithETag(resp.Header.Get("ETag")),
+			) // caching is best-effort
+		}
+		resp.Header = stored
+		if fromCache != "" {
+			resp.Header.Set(HeaderFromCache, fromCache)
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	resp.Request = req
+	return resp
+}
+
+// expires returns expiry time of response, and reports whether the response may be stored.
+// Response without explicit freshness is stored as stale, and revalidated on next request.
+func (t *Transport) expires(resp *http.Response) (time.Time, bool) {
+	now := t.now()
+	cc := parseCacheControl(resp.Header)
+	if cc.has("no-store") {
+		return time.Time{}, false
+	}
+	for _, name := range varyNames(resp.Header) {
+		if name == "*" { // varies on other than request headers
+			return time.Time{}, false
+		}
+	}
+	if cc.has("no-cache") {
+		return now, true
+	}
+	if v, ok := cc["max-age"]; ok {
+		sec, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return now, true
+		}
+		if age, err := strconv.ParseInt(resp.Header.Get("Age"), 10, 64); err == nil {
+			sec -= age
+		}
+		return now.Add(time.Duration(sec) * time.Second), true
+	}
+	if v := resp.Header.Get("Expires"); v != "" {
+		expires, err := http.ParseTime(v)
+		if err != nil {
+			return now, true // invalid Expires means already expired
+		}
+		if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+			return now.Add(expires.Sub(date)), true // not affected by clock skew of server
+		}
+		return expires, true
+	}
+	return now, true
+}
+
+// stale returns cached response marked stale
+func stale(resp *http.Response) *http.Response {
+	resp.Header.Add("Warning", `111 - "Revalidation Failed"`)
+	return resp
+}
+
+// varyNames returns canonical header names listed in Vary header
+func varyNames(h http.Header) []string {
+	var names []string
+	for _, line := range h.Values("Vary") {
+		for _, name := range strings.Split(line, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
+
+func cacheableStatus(code int) bool {
+	switch code {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusMultipleChoices,
+		http.StatusMovedPermanently, http.StatusNotFound, http.StatusGone:
+		return true
+	}
+	return false
+}
+
+// cacheControl is directives of Cache-Control header
+type cacheControl map[string]string
+
+func parseCacheControl(h http.Header) cacheControl {
+	cc := cacheControl{}
+	for _, line := range h.Values("Cache-Control") {
+		for _, directive := range strings.Split(line, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				cc[name] = strings.Trim(strings.TrimSpace(value), `"`)
+			}
+		}
+	}
+	return cc
+}
+
+func (cc cacheControl) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
@@ -0,0 +1,241 @@
+package httpcache_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goark/gocli/cache"
+	"github.com/goark/gocli/cache/httpcache"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestClient(t *testing.T, opts ...httpcache.OptFunc) (*http.Client, *testClock) {
+	t.Helper()
+	clock := &testClock{now: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)}
+	store, err := cache.NewStore("app", cache.WithDir(t.TempDir()), cache.WithClock(clock.Now))
+	if err != nil {
+		t.Fatalf("cache.NewStore() is \"%v\", want nil error.", err)
+	}
+	return httpcache.New(store, append([]httpcache.OptFunc{httpcache.WithClock(clock.Now)}, opts...)...).Client(), clock
+}
+
+func get(t *testing.T, client *http.Client, url string) (*http.Response, string) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("Client.Get() is \"%v\", want nil error.", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading body is \"%v\", want nil error.", err)
+	}
+	return resp, string(body)
+}
+
+func TestTransportMaxAge(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = io.WriteString(w, "hello")
+	}))
+	defer ts.Close()
+	client, clock := newTestClient(t)
+
+	testCases := []struct {
+		advance   time.Duration
+		fromCache bool
+		hits      int32
+	}{
+		{advance: 0, fromCache: false, hits: 1},
+		{advance: 30 * time.Second, fromCache: true, hits: 1},
+		{advance: time.Minute, fromCache: false, hits: 2},
+	}
+	for _, tc := range testCases {
+		clock.now = clock.now.Add(tc.advance)
+		resp, body := get(t, client, ts.URL)
+		if body != "hello" {
+			t.Errorf("body = \"%s\", want \"hello\".", body)
+		}
+		if fromCache := resp.Header.Get(httpcache.HeaderFromCache) != ""; fromCache != tc.fromCache {
+			t.Errorf("from cache = %v, want %v.", fromCache, tc.fromCache)
+		}
+		if n := atomic.LoadInt32(&hits); n != tc.hits {
+			t.Errorf("server hits = %d, want %d.", n, tc.hits)
+		}
+	}
+}
+
+func TestTransportRevalidate(t *testing.T) {
+	var hits, notModified int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "no-cache")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = io.WriteString(w, "content")
+	}))
+	defer ts.Close()
+	client, _ := newTestClient(t)
+
+	for i := 0; i < 3; i++ {
+		resp, body := get(t, client, ts.URL)
+		if body != "content" || resp.StatusCode != http.StatusOK {
+			t.Errorf("response = %d \"%s\", want 200 \"content\".", resp.StatusCode, body)
+		}
+	}
+	if h, nm := atomic.LoadInt32(&hits), atomic.LoadInt32(&notModified); h != 3 || nm != 2 {
+		t.Errorf("server hits = %d (not modified %d), want 3 (2).", h, nm)
+	}
+}
+
+func TestTransportNoStore(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Cache-Control", "no-store, max-age=60")
+		_, _ = io.WriteString(w, "secret")
+	}))
+	defer ts.Close()
+	client, _ := newTestClient(t)
+	for i := 0; i < 2; i++ {
+		get(t, client, ts.URL)
+	}
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("server hits = %d, want 2.", n)
+	}
+}
+
+func TestTransportOffline(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now().UTC()
+		w.Header().Set("Date", now.Format(http.TimeFormat))
+		w.Header().Set("Expires", now.Add(time.Hour).Format(http.TimeFormat))
+		_, _ = io.WriteString(w, "cached")
+	}))
+	url := ts.URL
+	clock := &testClock{now: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)}
+	store, err := cache.NewStore("app", cache.WithDir(t.TempDir()), cache.WithClock(clock.Now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	online := httpcache.New(store, httpcache.WithClock(clock.Now)).Client()
+	offline := httpcache.New(store, httpcache.WithClock(clock.Now), httpcache.WithOffline(true)).Client()
+	get(t, online, url)
+	ts.Close() // network fails
+
+	if resp, body := get(t, online, url); body != "cached" || resp.Header.Get(httpcache.HeaderFromCache) == "" {
+		t.Errorf("fresh response = \"%s\", want \"cached\" from cache.", body)
+	}
+	clock.now = clock.now.Add(2 * time.Hour)
+	if resp, err := online.Get(url); err == nil {
+		resp.Body.Close()
+		t.Errorf("Client.Get() of stale response without offline mode is nil error, want error.")
+	}
+	resp, body := get(t, offline, url)
+	if body != "cached" || resp.Header.Get("Warning") == "" {
+		t.Errorf("stale response in offline mode = \"%s\" (Warning: %q), want \"cached\" with warning.", body, resp.Header.Get("Warning"))
+	}
+}
+
+func TestTransportVary(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		if r.URL.Path == "/any" {
+			w.Header().Set("Vary", "*")
+		} else {
+			w.Header().Set("Vary", "Accept")
+		}
+		_, _ = io.WriteString(w, "accept: "+r.Header.Get("Accept"))
+	}))
+	defer ts.Close()
+	client, _ := newTestClient(t)
+
+	testCases := []struct {
+		path      string
+		accept    string
+		fromCache bool
+		hits      int32
+	}{
+		{path: "/", accept: "text/plain", fromCache: false, hits: 1},
+		{path: "/", accept: "text/plain", fromCache: true, hits: 1},
+		{path: "/", accept: "application/json", fromCache: false, hits: 2},
+		{path: "/", accept: "application/json", fromCache: true, hits: 2},
+		{path: "/any", accept: "text/plain", fromCache: false, hits: 3},
+		{path: "/any", accept: "text/plain", fromCache: false, hits: 4},
+	}
+	for _, tc := range testCases {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+tc.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", tc.accept)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Client.Do() is \"%v\", want nil error.", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "accept: "+tc.accept {
+			t.Errorf("body = \"%s\", want \"accept: %s\".", body, tc.accept)
+		}
+		if fromCache := resp.Header.Get(httpcache.HeaderFromCache) != ""; fromCache != tc.fromCache {
+			t.Errorf("%s (Accept: %s) from cache = %v, want %v.", tc.path, tc.accept, fromCache, tc.fromCache)
+		}
+		if n := atomic.LoadInt32(&hits); n != tc.hits {
+			t.Errorf("server hits = %d, want %d.", n, tc.hits)
+		}
+	}
+}
+
+func TestTransportAuthorization(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = io.WriteString(w, "user: "+r.Header.Get("Authorization"))
+	}))
+	defer ts.Close()
+	client, _ := newTestClient(t)
+
+	for _, token := range []string{"Bearer alice", "Bearer bob"} {
+		req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", token)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Client.Do() is \"%v\", want nil error.", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "user: "+token || resp.Header.Get(httpcache.HeaderFromCache) != "" {
+			t.Errorf("body = \"%s\" (from cache %q), want \"user: %s\" from server.", body, resp.Header.Get(httpcache.HeaderFromCache), token)
+		}
+	}
+	if resp, body := get(t, client, ts.URL); body != "user: " || resp.Header.Get(httpcache.HeaderFromCache) != "" {
+		t.Errorf("anonymous body = \"%s\", want \"user: \" from server.", body)
+	}
+	if n := atomic.LoadInt32(&hits); n != 3 {
+		t.Errorf("server hits = %d, want 3.", n)
+	}
+}
@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrLocked is error for lock held by other process (or goroutine)
+var ErrLocked = errors.New("cache entry is locked")
+
+const (
+	lockExt   = ".lock"
+	tmpPrefix = ".tmp-"
+)
+
+// lockRetryInterval is interval of retrying lock held by other in lockPathContext
+var lockRetryInterval = 10 * time.Millisecond
+
+// fileLock is advisory lock of file (flock on Unix, LockFileEx on Windows, and none on solaris, aix, plan9 and js/wasm).
+// Locks are held per open file, so they exclude each other across processes and goroutines.
+type fileLock struct {
+	file *os.File
+}
+
+// lockPath locks file at path (created if not exists), blocking until the lock is acquired
+func lockPath(path string, exclusive bool) (*fileLock, error) {
+	return openLock(path, exclusive, true)
+}
+
+// tryLockPath locks file at path without blocking; it returns ErrLocked if the lock is held by other
+func tryLockPath(path string, exclusive bool) (*fileLock, error) {
+	return openLock(path, exclusive, false)
+}
+
+// lockPathContext locks file at path like lockPath, but gives up when ctx is done
+func lockPathContext(ctx context.Context, path string, exclusive bool) (*fileLock, error) {
+	if ctx.Done() == nil { // never canceled
+		return lockPath(path, exclusive)
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		l, err := tryLockPath(path, exclusive)
+		if !errors.Is(err, ErrLocked) {
+			return l, err
+		}
+		timer := time.NewTimer(lockRetryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// openLock opens and locks file at path. If the file is removed (or replaced) by other while waiting for the lock,
+// the lock is taken again on the current file, so lock holders always share the same file.
+func openLock(path string, exclusive, wait bool) (*fileLock, error) {
+	for {
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600) //nolint:gosec
+		if err != nil {
+			return nil, err
+		}
+		if err := lockFile(file, exclusive, wait); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		if isCurrent(file, path) {
+			return &fileLock{file: file}, nil
+		}
+		_ = unlockFile(file)
+		_ = file.Close()
+	}
+}
+
+// isCurrent reports whether file is still the file at path
+func isCurrent(file *os.File, path string) bool {
+	fi, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	pfi, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(fi, pfi)
+}
+
+// Unlock releases the lock
+func (l *fileLock) Unlock() error {
+	err := unlockFile(l.file)
+	if cerr := l.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Remove removes the lock file while holding the lock, so waiters retry on a new file (see openLock).
+// It fails on platforms which do not allow removing open files (e.g. Windows).
+func (l *fileLock) Remove() error {
+	return os.Remove(l.file.Name())
+}
+
+// lockName returns name of lock file for entry file
+func lockName(name string) string {
+	return strings.TrimSuffix(name, filepath.Ext(name)) + lockExt
+}
+
+// writeFileAtomic writes data to temporary file in the same directory and renames it to path,
+// so readers see either the old or the new content.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), tmpPrefix+"*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) //nolint:errcheck // no-op after rename
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
@@ -0,0 +1,14 @@
+//go:build !windows && (!unix || solaris || aix)
+
+package cache
+
+import "os"
+
+// lockFile does nothing on platforms without file locking (e.g. js/wasm, plan9) or flock(2) (solaris, aix)
+func lockFile(file *os.File, exclusive, wait bool) error {
+	return nil
+}
+
+func unlockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,93 @@
+//go:build (unix && !solaris && !aix) || windows
+
+package cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestTryLockPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "entry.lock")
+	l, err := lockPath(path, true)
+	if err != nil {
+		t.Fatalf("lockPath() is \"%v\", want nil error.", err)
+	}
+	if _, err := tryLockPath(path, false); !errors.Is(err, ErrLocked) {
+		t.Errorf("tryLockPath() while locked is \"%v\", want \"%v\".", err, ErrLocked)
+	}
+	if err := l.Unlock(); err != nil {
+		t.Fatalf("Unlock() is \"%v\", want nil error.", err)
+	}
+
+	l1, err := tryLockPath(path, false)
+	if err != nil {
+		t.Fatalf("tryLockPath() is \"%v\", want nil error.", err)
+	}
+	defer l1.Unlock() //nolint:errcheck
+	l2, err := tryLockPath(path, false)
+	if err != nil {
+		t.Errorf("tryLockPath() of shared lock is \"%v\", want nil error.", err)
+	} else {
+		_ = l2.Unlock()
+	}
+	if _, err := tryLockPath(path, true); !errors.Is(err, ErrLocked) {
+		t.Errorf("tryLockPath() of exclusive lock is \"%v\", want \"%v\".", err, ErrLocked)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "index.json")
+	for _, s := range []string{"old", "new"} {
+		if err := writeFileAtomic(path, []byte(s)); err != nil {
+			t.Fatalf("writeFileAtomic() is \"%v\", want nil error.", err)
+		}
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "new" {
+		t.Errorf("content = \"%s\" (%v), want \"new\".", data, err)
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(dir, tmpPrefix+"*")); len(tmps) != 0 {
+		t.Errorf("temporary files = %v, want none.", tmps)
+	}
+}
+
+func TestLockPathRemoved(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("lock file in use cannot be removed on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "entry.lock")
+	l, err := lockPath(path, true)
+	if err != nil {
+		t.Fatalf("lockPath() is \"%v\", want nil error.", err)
+	}
+	locked := make(chan *fileLock)
+	go func() {
+		l2, err := lockPath(path, true) // waits for l, then retries on new file
+		if err != nil {
+			t.Errorf("lockPath() is \"%v\", want nil error.", err)
+		}
+		locked <- l2
+	}()
+	time.Sleep(20 * time.Millisecond) // let waiter open the old file
+	if err := l.Remove(); err != nil {
+		t.Fatalf("Remove() is \"%v\", want nil error.", err)
+	}
+	_ = l.Unlock()
+
+	l2 := <-locked
+	if l2 == nil {
+		return
+	}
+	defer l2.Unlock() //nolint:errcheck
+	if !isCurrent(l2.file, path) {
+		t.Errorf("lock is taken on removed file, want file at %s.", path)
+	}
+	if _, err := tryLockPath(path, true); !errors.Is(err, ErrLocked) {
+		t.Errorf("tryLockPath() while locked is \"%v\", want \"%v\".", err, ErrLocked)
+	}
+}
@@ -0,0 +1,50 @@
+//go:build windows
+
+package cache
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modkernel32      = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = modkernel32.NewProc("LockFileEx")
+	procUnlockFileEx = modkernel32.NewProc("UnlockFileEx")
+)
+
+const (
+	lockfileFailImmediately = 0x00000001
+	lockfileExclusiveLock   = 0x00000002
+	errorLockViolation      = syscall.Errno(33)
+)
+
+func lockFile(file *os.File, exclusive, wait bool) error {
+	flags := uint32(0)
+	if exclusive {
+		flags |= lockfileExclusiveLock
+	}
+	if !wait {
+		flags |= lockfileFailImmediately
+	}
+	ol := new(syscall.Overlapped)
+	r1, _, err := procLockFileEx.Call(file.Fd(), uintptr(flags), 0, 1, 0, uintptr(unsafe.Pointer(ol))) //nolint:gosec
+	if r1 != 0 {
+		return nil
+	}
+	if errors.Is(err, errorLockViolation) {
+		return ErrLocked
+	}
+	return &os.PathError{Op: "LockFileEx", Path: file.Name(), Err: err}
+}
+
+func unlockFile(file *os.File) error {
+	ol := new(syscall.Overlapped)
+	r1, _, err := procUnlockFileEx.Call(file.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(ol))) //nolint:gosec
+	if r1 != 0 {
+		return nil
+	}
+	return &os.PathError{Op: "UnlockFileEx", Path: file.Name(), Err: err}
+}
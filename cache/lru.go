@@ -0,0 +1,229 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// indexFile is name of index file recording access times of entries in Store
+const indexFile = "index.json"
+
+// Usage is report of disk usage of Store
+type Usage struct {
+	Entries      int
+	Bytes        int64     // total size of entry files (compressed)
+	LogicalBytes int64     // total size of data in entries (uncompressed)
+	Oldest       time.Time // creation time of the oldest entry (zero if no entry)
+	OldestKey    string
+}
+
+// WithMaxSize returns function for setting max total size of entries in bytes (no limit if not positive).
+// Least recently used entries are evicted when the limit is exceeded.
+func WithMaxSize(size int64) OptFunc {
+	return func(s *Store) {
+		s.maxSize = size
+	}
+}
+
+// WithMaxEntries returns function for setting max number of entries (no limit if not positive).
+// Least recently used entries are evicted when the limit is exceeded.
+func WithMaxEntries(n int) OptFunc {
+	return func(s *Store) {
+		s.maxEntries = n
+	}
+}
+
+// Usage returns disk usage of Store
+func (s *Store) Usage() (Usage, error) {
+	u := Usage{}
+	infos, err := s.entryInfos(nil)
+	if err != nil {
+		return u, err
+	}
+	for _, info := range infos {
+		meta, _, err := readEntry(filepath.Join(s.dir, info.name), false)
+		if err != nil {
+			continue // removed or broken
+		}
+		u.Entries++
+		u.Bytes += info.size
+		u.LogicalBytes += meta.Size
+		if u.Oldest.IsZero() || meta.Created.Before(u.Oldest) {
+			u.Oldest, u.OldestKey = meta.Created, meta.Key
+		}
+	}
+	return u, nil
+}
+
+// accessInterval is minimum interval of recording access time of the same entry by Get
+const accessInterval = time.Minute
+
+// touch records access time of entry (or removes it from index if remove is true)
+func (s *Store) touch(name string, remove bool) error {
+	unlock, err := s.lockIndex()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	idx := s.loadIndex()
+	now := s.now()
+	if remove {
+		delete(idx, name)
+	} else {
+		idx[name] = now
+	}
+	if err := s.saveIndex(idx); err != nil {
+		return err
+	}
+	s.recentMutex.Lock()
+	defer s.recentMutex.Unlock()
+	if s.recent == nil {
+		s.recent = map[string]time.Time{}
+	}
+	if remove {
+		delete(s.recent, name)
+	} else {
+		s.recent[name] = now
+	}
+	return nil
+}
+
+// access records access time of entry like touch, but skips it if recorded within accessInterval,
+// because rewriting index under the index lock on every read is expensive.
+func (s *Store) access(name string) {
+	now := s.now()
+	s.recentMutex.Lock()
+	last, ok := s.recent[name]
+	s.recentMutex.Unlock()
+	if ok && !now.Before(last) && now.Sub(last) < accessInterval {
+		return
+	}
+	_ = s.touch(name, false)
+}
+
+// evict removes least recently used entries exceeding limits of Store, except entry keep
+func (s *Store) evict(keep string) error {
+	if s.maxSize <= 0 && s.maxEntries <= 0 {
+		return nil
+	}
+	unlock, err := s.lockIndex()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	idx := s.loadIndex()
+	infos, err := s.entryInfos(idx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].accessed.Before(infos[j].accessed) })
+	total := int64(0)
+	present := map[string]bool{}
+	for _, info := range infos {
+		total += info.size
+		present[info.name] = true
+	}
+	for name := range idx { // drop entries removed by others
+		if !present[name] {
+			delete(idx, name)
+		}
+	}
+	count := len(infos)
+	for _, info := range infos {
+		if (s.maxEntries <= 0 || count <= s.maxEntries) && (s.maxSize <= 0 || total <= s.maxSize) {
+			break
+		}
+		if info.name == keep {
+			continue
+		}
+		l, err := tryLockPath(filepath.Join(s.dir, lockName(info.name)), true)
+		if err != nil {
+			continue // in use by other
+		}
+		err = os.Remove(filepath.Join(s.dir, info.name))
+		_ = l.Unlock()
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		delete(idx, info.name)
+		count--
+		total -= info.size
+	}
+	return s.saveIndex(idx)
+}
+
+type entryInfo struct {
+	name     string
+	size     int64
+	accessed time.Time
+}
+
+// entryInfos returns entry files in Store. Access time is taken from idx, or modification time of file.
+func (s *Store) entryInfos(idx map[string]time.Time) ([]entryInfo, error) {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	infos := []entryInfo{}
+	for _, de := range dirEntries {
+		if de.IsDir() || !strings.HasSuffix(de.Name(), entryExt) {
+			continue
+		}
+		fi, err := de.Info()
+		if err != nil {
+			continue // removed
+		}
+		accessed, ok := idx[de.Name()]
+		if !ok {
+			accessed = fi.ModTime()
+		}
+		infos = append(infos, entryInfo{name: de.Name(), size: fi.Size(), accessed: accessed})
+	}
+	return infos, nil
+}
+
+// loadIndex returns access times of entries (empty if index file is missing or broken)
+func (s *Store) loadIndex() map[string]time.Time {
+	idx := map[string]time.Time{}
+	if data, err := os.ReadFile(filepath.Join(s.dir, indexFile)); err == nil {
+		if err := json.Unmarshal(data, &idx); err != nil {
+			return map[string]time.Time{}
+		}
+	}
+	return idx
+}
+
+func (s *Store) saveIndex(idx map[string]time.Time) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(s.dir, indexFile), data)
+}
+
+// lockIndex locks index file exclusively, and returns function to unlock
+func (s *Store) lockIndex() (func(), error) {
+	s.mutex.Lock()
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		s.mutex.Unlock()
+		return nil, err
+	}
+	l, err := lockPath(filepath.Join(s.dir, lockName(indexFile)), true)
+	if err != nil {
+		s.mutex.Unlock()
+		return nil, err
+	}
+	return func() {
+		_ = l.Unlock()
+		s.mutex.Unlock()
+	}, nil
+}
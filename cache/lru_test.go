@@ -0,0 +1,138 @@
+package cache_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goark/gocli/cache"
+)
+
+func TestStoreEvictMaxEntries(t *testing.T) {
+	s, clock := newTestStore(t, cache.WithMaxEntries(2))
+	for _, key := range []string{"a", "b"} {
+		if err := s.Set(key, []byte(key), 0); err != nil {
+			t.Fatalf("Store.Set() is \"%v\", want nil error.", err)
+		}
+		clock.Advance(time.Minute)
+	}
+	if _, _, err := s.Get("a"); err != nil { // "b" becomes least recently used
+		t.Fatalf("Store.Get() is \"%v\", want nil error.", err)
+	}
+	clock.Advance(time.Minute)
+	if err := s.Set("c", []byte("c"), 0); err != nil {
+		t.Fatalf("Store.Set() is \"%v\", want nil error.", err)
+	}
+
+	testCases := []struct {
+		key string
+		err error
+	}{
+		{key: "a", err: nil},
+		{key: "b", err: cache.ErrNotFound},
+		{key: "c", err: nil},
+	}
+	for _, tc := range testCases {
+		if _, _, err := s.Get(tc.key); !errors.Is(err, tc.err) {
+			t.Errorf("Store.Get(\"%v\") is \"%v\", want \"%v\".", tc.key, err, tc.err)
+		}
+	}
+}
+
+func TestStoreEvictMaxSize(t *testing.T) {
+	s, clock := newTestStore(t, cache.WithMaxSize(1024))
+	data := make([]byte, 400)
+	for _, key := range []string{"a", "b", "c"} {
+		if err := s.Set(key, data, 0); err != nil {
+			t.Fatalf("Store.Set() is \"%v\", want nil error.", err)
+		}
+		clock.Advance(time.Minute)
+	}
+	u, err := s.Usage()
+	if err != nil {
+		t.Fatalf("Store.Usage() is \"%v\", want nil error.", err)
+	}
+	if u.Entries != 2 || u.Bytes > 1024 || u.OldestKey != "b" {
+		t.Errorf("Store.Usage() = %+v, want 2 entries from \"b\".", u)
+	}
+
+	if err := s.Set("large", make([]byte, 2048), 0); err != nil {
+		t.Fatalf("Store.Set() is \"%v\", want nil error.", err)
+	}
+	if _, _, err := s.Get("large"); err != nil {
+		t.Errorf("Store.Get() of the last entry is \"%v\", want nil error.", err)
+	}
+	if u, err := s.Usage(); err != nil || u.Entries != 1 {
+		t.Errorf("Store.Usage() = %+v (%v), want 1 entry.", u, err)
+	}
+}
+
+func TestStoreUsageEmpty(t *testing.T) {
+	s, _ := newTestStore(t)
+	u, err := s.Usage()
+	if err != nil || u.Entries != 0 || u.Bytes != 0 || !u.Oldest.IsZero() {
+		t.Errorf("Store.Usage() = %+v (%v), want empty.", u, err)
+	}
+}
+
+func TestStoreGetAccessInterval(t *testing.T) {
+	s, clock := newTestStore(t)
+	if err := s.Set("a", []byte("a"), 0); err != nil {
+		t.Fatalf("Store.Set() is \"%v\", want nil error.", err)
+	}
+	index := func() string {
+		data, err := os.ReadFile(filepath.Join(s.Dir(), "index.json"))
+		if err != nil {
+			t.Fatalf("reading index is \"%v\", want nil error.", err)
+		}
+		return string(data)
+	}
+
+	testCases := []struct {
+		advance time.Duration
+		updated bool
+	}{
+		{advance: 10 * time.Second, updated: false},
+		{advance: 10 * time.Second, updated: false},
+		{advance: time.Minute, updated: true},
+		{advance: 0, updated: false},
+	}
+	for _, tc := range testCases {
+		clock.Advance(tc.advance)
+		before := index()
+		if _, _, err := s.Get("a"); err != nil {
+			t.Fatalf("Store.Get() is \"%v\", want nil error.", err)
+		}
+		if updated := index() != before; updated != tc.updated {
+			t.Errorf("index updated by Store.Get() after %v = %v, want %v.", tc.advance, updated, tc.updated)
+		}
+	}
+}
+
+func TestStoreUsageLegacy(t *testing.T) {
+	s, _ := newTestStore(t)
+	if err := s.Set("legacy", []byte("hello"), 0); err != nil {
+		t.Fatalf("Store.Set() is \"%v\", want nil error.", err)
+	}
+	paths, err := filepath.Glob(filepath.Join(s.Dir(), "*.cache"))
+	if err != nil || len(paths) != 1 {
+		t.Fatalf("entry files = %v (%v), want 1 file.", paths, err)
+	}
+	// entry written before size and encoding were recorded
+	if err := os.WriteFile(paths[0], []byte(`{"key":"legacy","created":"2023-04-01T00:00:00Z","expires":"0001-01-01T00:00:00Z"}`+"\nhello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := s.Usage()
+	if err != nil {
+		t.Fatalf("Store.Usage() is \"%v\", want nil error.", err)
+	}
+	if u.Entries != 1 || u.LogicalBytes != 5 {
+		t.Errorf("Store.Usage() = %+v, want 1 entry of 5 bytes.", u)
+	}
+	if data, meta, err := s.Get("legacy"); err != nil || string(data) != "hello" || meta.Size != 5 {
+		t.Errorf("Store.Get() = \"%s\", %+v (%v), want \"hello\" of size 5.", data, meta, err)
+	}
+}
@@ -0,0 +1,258 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultTempAge is default age of temporary files to be regarded as leftovers of crashed writers
+const DefaultTempAge = time.Hour
+
+// PrunePolicy is policy of Prune
+type PrunePolicy struct {
+	MaxAge       time.Duration // remove entries and blobs not used within MaxAge (no limit if not positive)
+	ExpiredGrace time.Duration // keep expired entries for ExpiredGrace (e.g. for stale-if-error)
+	Version      string        // remove entries of other schema version (version of Store if empty)
+	TempAge      time.Duration // remove temporary files older than TempAge (DefaultTempAge if not positive)
+}
+
+// PruneSummary is summary of Prune and Clear
+type PruneSummary struct {
+	Removed int   // number of removed files
+	Freed   int64 // total size of removed files in bytes
+	Skipped int   // number of entries skipped because they are locked by others
+}
+
+// Prune removes expired, unused, broken or orphaned files in Dir(appName) along policy (see Store.Prune)
+func Prune(appName string, policy PrunePolicy) (PruneSummary, error) {
+	s, err := newPruneStore(appName)
+	if err != nil {
+		return PruneSummary{}, err
+	}
+	return s.Prune(policy)
+}
+
+// Clear removes entries, blobs and other files managed by Store in Dir(appName) (see Store.Clear)
+func Clear(appName string) (PruneSummary, error) {
+	s, err := newPruneStore(appName)
+	if err != nil {
+		return PruneSummary{}, err
+	}
+	return s.Clear()
+}
+
+func newPruneStore(appName string) (*Store, error) {
+	if len(appName) == 0 { // never touch whole user cache directory
+		return nil, fmt.Errorf("%w: app %q", ErrInvalidDir, appName)
+	}
+	return NewStore(appName)
+}
+
+// Prune removes files in Store along policy:
+// expired, unused (MaxAge), broken or other version entries, old blobs (MaxAge),
+// lock files without entry, and temporary files left by crashed writers.
+// Entries locked by other processes are skipped. Unknown files are kept.
+func (s *Store) Prune(policy PrunePolicy) (PruneSummary, error) {
+	return s.prune(policy, false)
+}
+
+// Clear removes all files managed by Store: entries, lock files, temporary files, index and blobs.
+// Entries locked by other processes, recent temporary files (possibly being written) and unknown files are kept.
+func (s *Store) Clear() (PruneSummary, error) {
+	return s.prune(PrunePolicy{}, true)
+}
+
+func (s *Store) prune(policy PrunePolicy, all bool) (PruneSummary, error) {
+	sum := PruneSummary{}
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return sum, nil
+		}
+		return sum, err
+	}
+	if policy.Version == "" {
+		policy.Version = s.version
+	}
+	if policy.TempAge <= 0 {
+		policy.TempAge = DefaultTempAge
+	}
+	unlock, err := s.lockIndex()
+	if err != nil {
+		return sum, err
+	}
+	defer unlock()
+	idx := s.loadIndex()
+	now := s.now()
+
+	locks := []string{}
+	for _, de := range dirEntries {
+		name := de.Name()
+		path := filepath.Join(s.dir, name)
+		switch {
+		case name == indexFile || name == lockName(indexFile):
+		case strings.HasPrefix(name, tmpPrefix):
+			err = sum.removeTemp(path, policy.TempAge)
+		case isHashName(name, lockExt):
+			locks = append(locks, name)
+		case isHashName(name, entryExt):
+			var removed bool
+			removed, err = s.pruneEntry(&sum, name, idx, policy, now, all)
+			if removed {
+				delete(idx, name)
+			}
+		case name == blobDir && de.IsDir():
+			err = sum.pruneBlobs(path, policy, all)
+		}
+		if err != nil {
+			return sum, err
+		}
+	}
+	for _, name := range locks { // lock files without entry
+		if _, err := os.Stat(filepath.Join(s.dir, strings.TrimSuffix(name, lockExt)+entryExt)); !errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		l, err := tryLockPath(filepath.Join(s.dir, name), true)
+		if err != nil {
+			continue // in use by other
+		}
+		sum.removeLock(l)
+		_ = l.Unlock()
+	}
+	if all {
+		idx = map[string]time.Time{}
+	}
+	return sum, s.saveIndex(idx)
+}
+
+// pruneEntry removes entry file name if it matches policy, and reports whether it is removed
+func (s *Store) pruneEntry(sum *PruneSummary, name string, idx map[string]time.Time, policy PrunePolicy, now time.Time, all bool) (bool, error) {
+	path := filepath.Join(s.dir, name)
+	l, err := tryLockPath(filepath.Join(s.dir, lockName(name)), true)
+	if err != nil {
+		if errors.Is(err, ErrLocked) {
+			sum.Skipped++
+			return false, nil
+		}
+		return false, err
+	}
+	defer l.Unlock() //nolint:errcheck
+	if !all {
+		meta, _, err := readEntry(path, false)
+		switch {
+		case err != nil:
+			if !errors.Is(err, ErrBrokenEntry) {
+				return false, nil // removed by other
+			}
+		case policy.Version != "" && meta.Version != policy.Version:
+		case meta.Expired(now.Add(-policy.ExpiredGrace)):
+		case policy.MaxAge > 0 && s.accessed(path, name, idx).Before(now.Add(-policy.MaxAge)):
+		default:
+			return false, nil
+		}
+	}
+	return true, sum.remove(path)
+}
+
+// accessed returns last access time of entry file from index, or modification time of file
+func (s *Store) accessed(path, name string, idx map[string]time.Time) time.Time {
+	if t, ok := idx[name]; ok {
+		return t
+	}
+	if fi, err := os.Stat(path); err == nil {
+		return fi.ModTime()
+	}
+	return time.Time{}
+}
+
+// pruneBlobs removes blobs not used within MaxAge (or all blobs) and temporary files in blob directory.
+// Unknown files are kept.
+func (sum *PruneSummary) pruneBlobs(dir string, policy PrunePolicy, all bool) error {
+	shards, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, shard := range shards {
+		path := filepath.Join(dir, shard.Name())
+		if !shard.IsDir() {
+			if strings.HasPrefix(shard.Name(), tmpPrefix) {
+				if err := sum.removeTemp(path, policy.TempAge); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if len(shard.Name()) != 2 {
+			continue
+		}
+		blobs, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, blob := range blobs {
+			if blob.IsDir() || checkDigest(shard.Name()+blob.Name()) != nil {
+				continue
+			}
+			fi, err := blob.Info()
+			if err != nil {
+				continue // removed by other
+			}
+			if all || (policy.MaxAge > 0 && time.Since(fi.ModTime()) > policy.MaxAge) {
+				if err := sum.remove(filepath.Join(path, blob.Name())); err != nil {
+					return err
+				}
+			}
+		}
+		_ = os.Remove(path) // only if empty
+	}
+	return nil
+}
+
+// isHashName reports whether name is hex-encoded SHA-256 with ext (name of entry or lock file)
+func isHashName(name, ext string) bool {
+	hash, ok := strings.CutSuffix(name, ext)
+	return ok && checkDigest(hash) == nil
+}
+
+// removeTemp removes temporary file older than age (newer one is possibly being written)
+func (sum *PruneSummary) removeTemp(path string, age time.Duration) error {
+	fi, err := os.Stat(path)
+	if err != nil || time.Since(fi.ModTime()) < age {
+		return nil // removed or possibly being written
+	}
+	return sum.remove(path)
+}
+
+// removeLock removes lock file while holding it (best-effort; kept if it cannot be removed while open)
+func (sum *PruneSummary) removeLock(l *fileLock) {
+	fi, err := l.file.Stat()
+	if err != nil {
+		return
+	}
+	if err := l.Remove(); err == nil {
+		sum.Removed++
+		sum.Freed += fi.Size()
+	}
+}
+
+// remove removes file at path and counts it
+func (sum *PruneSummary) remove(path string) error {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return nil //nolint:nilerr // removed by other
+	}
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	sum.Removed++
+	sum.Freed += fi.Size()
+	return nil
+}
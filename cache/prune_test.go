@@ -0,0 +1,158 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goark/gocli/cache"
+)
+
+func TestStorePrune(t *testing.T) {
+	s, clock := newTestStore(t, cache.WithVersion("v2"))
+	for _, key := range []string{"fresh", "expired", "unused"} {
+		ttl := time.Duration(0)
+		if key == "expired" {
+			ttl = time.Minute
+		}
+		if err := s.Set(key, []byte(key), ttl); err != nil {
+			t.Fatalf("Store.Set() is \"%v\", want nil error.", err)
+		}
+	}
+	old, err := cache.NewStore("app", cache.WithDir(s.Dir()), cache.WithClock(clock.Now), cache.WithVersion("v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := old.Set("old version", []byte("x"), 0); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(2 * time.Hour)
+	if _, _, err := s.Get("fresh"); err != nil {
+		t.Fatal(err)
+	}
+
+	leftover := filepath.Join(s.Dir(), ".tmp-123")
+	recent := filepath.Join(s.Dir(), ".tmp-456")
+	orphan := filepath.Join(s.Dir(), strings.Repeat("0", 64)+".lock")
+	unknown := filepath.Join(s.Dir(), "access.log")
+	for _, path := range []string{leftover, recent, orphan, unknown} {
+		if err := os.WriteFile(path, []byte("12345"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	past := time.Now().Add(-2 * cache.DefaultTempAge)
+	if err := os.Chtimes(leftover, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	sum, err := s.Prune(cache.PrunePolicy{MaxAge: time.Hour})
+	if err != nil {
+		t.Fatalf("Store.Prune() is \"%v\", want nil error.", err)
+	}
+	if sum.Removed < 5 || sum.Freed <= 0 || sum.Skipped != 0 {
+		t.Errorf("Store.Prune() = %+v, want expired, unused, old version, leftover and orphan removed.", sum)
+	}
+
+	if _, _, err := s.Get("fresh"); err != nil {
+		t.Errorf("Store.Get() of fresh entry is \"%v\", want nil error.", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(s.Dir(), "*.cache"))
+	if len(files) != 1 {
+		t.Errorf("entry files = %v, want 1 file.", files)
+	}
+	testCases := []struct {
+		path  string
+		exist bool
+	}{
+		{path: leftover, exist: false},
+		{path: recent, exist: true},
+		{path: orphan, exist: false},
+		{path: unknown, exist: true},
+	}
+	for _, tc := range testCases {
+		if _, err := os.Stat(tc.path); (err == nil) != tc.exist {
+			t.Errorf("%v exists = %v, want %v.", filepath.Base(tc.path), err == nil, tc.exist)
+		}
+	}
+}
+
+func TestStoreClear(t *testing.T) {
+	s, clock := newTestStore(t)
+	for _, key := range []string{"a", "busy"} {
+		if err := s.Set(key, []byte(key), time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := cache.NewBlobStore("app", cache.WithDir(s.Dir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := b.Put(strings.NewReader("blob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown := []string{
+		filepath.Join(s.Dir(), "notes.cache"),
+		filepath.Join(s.Dir(), "data", "file.txt"),
+		filepath.Join(s.Dir(), "blobs", "README"),
+	}
+	for _, path := range unknown {
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("keep"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clock.Advance(time.Hour)
+
+	// "busy" is locked while computing
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _, _ = s.GetOrCompute(context.Background(), "busy", time.Hour, func(context.Context) ([]byte, error) {
+			close(started)
+			<-release
+			return []byte("busy"), nil
+		})
+	}()
+	<-started
+	sum, err := s.Clear()
+	close(release)
+	<-done
+	if err != nil {
+		t.Fatalf("Store.Clear() is \"%v\", want nil error.", err)
+	}
+	if sum.Skipped != 1 || sum.Removed < 2 {
+		t.Errorf("Store.Clear() = %+v, want 1 skipped.", sum)
+	}
+	if b.Has(digest) {
+		t.Errorf("blob is not cleared.")
+	}
+	if _, _, err := s.Get("a"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("Store.Get() of cleared entry is \"%v\", want \"%v\".", err, cache.ErrNotFound)
+	}
+	if _, _, err := s.Get("busy"); err != nil {
+		t.Errorf("Store.Get() of locked entry is \"%v\", want nil error.", err)
+	}
+	for _, path := range unknown {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("file not managed by Store is removed: %v", err)
+		}
+	}
+}
+
+func TestPruneInvalidApp(t *testing.T) {
+	if _, err := cache.Clear(""); !errors.Is(err, cache.ErrInvalidDir) {
+		t.Errorf("cache.Clear(\"\") is \"%v\", want \"%v\".", err, cache.ErrInvalidDir)
+	}
+	if _, err := cache.Prune("../foo", cache.PrunePolicy{}); !errors.Is(err, cache.ErrInvalidDir) {
+		t.Errorf("cache.Prune(\"../foo\") is \"%v\", want \"%v\".", err, cache.ErrInvalidDir)
+	}
+}
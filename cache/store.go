@@ -0,0 +1,339 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Errors of cache store
+var (
+	ErrNotFound    = errors.New("cache entry not found")
+	ErrInvalidKey  = errors.New("invalid cache key")
+	ErrInvalidDir  = errors.New("invalid cache directory")
+	ErrBrokenEntry = errors.New("broken cache entry")
+)
+
+// entryExt is extension of entry file in Store
+const entryExt = ".cache"
+
+// Metadata is metadata of cache entry
+type Metadata struct {
+	Key         string    `json:"key"`
+	Created     time.Time `json:"created"`
+	Expires     time.Time `json:"expires"` // zero if the entry never expires
+	ContentType string    `json:"content_type,omitempty"`
+	ETag        string    `json:"etag,omitempty"`
+	Version     string    `json:"version,omitempty"`  // schema version of Store
+	Encoding    string    `json:"encoding,omitempty"` // name of codec compressing data (empty if not compressed)
+	Size        int64     `json:"size"`               // logical (uncompressed) size of data
+}
+
+// Expired reports whether the entry is expired at time now
+func (m *Metadata) Expired(now time.Time) bool {
+	return !m.Expires.IsZero() && !now.Before(m.Expires)
+}
+
+// Store is key-value cache store in user cache directory
+type Store struct {
+	dir         string
+	now         func() time.Time
+	maxSize     int64
+	maxEntries  int
+	maxStale    time.Duration
+	version     string
+	codec       string
+	mutex       sync.Mutex
+	recentMutex sync.Mutex
+	recent      map[string]time.Time // access times recorded in index by this Store
+}
+
+// OptFunc is self-referential function for functional options pattern
+type OptFunc func(*Store)
+
+// NewStore returns a new Store instance rooted at Dir(appName)
+func NewStore(appName string, opts ...OptFunc) (*Store, error) {
+	s := &Store{dir: Dir(appName), now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if len(s.dir) == 0 {
+		return nil, fmt.Errorf("%w: app %q", ErrInvalidDir, appName)
+	}
+	if _, ok := CodecFor(s.codec); len(s.codec) > 0 && !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoCodec, s.codec)
+	}
+	return s, nil
+}
+
+// WithDir returns function for setting root directory of Store instead of Dir(appName)
+func WithDir(dir string) OptFunc {
+	return func(s *Store) {
+		s.dir = dir
+	}
+}
+
+// WithClock returns function for setting clock of Store (for testing)
+func WithClock(now func() time.Time) OptFunc {
+	return func(s *Store) {
+		if now != nil {
+			s.now = now
+		}
+	}
+}
+
+// Dir returns root directory of Store
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// EntryOptFunc is self-referential function for functional options pattern (Set method)
+type EntryOptFunc func(*Metadata)
+
+// WithContentType returns function for setting content type of entry
+func WithContentType(contentType string) EntryOptFunc {
+	return func(m *Metadata) {
+		m.ContentType = contentType
+	}
+}
+
+// WithETag returns function for setting ETag of entry
+func WithETag(etag string) EntryOptFunc {
+	return func(m *Metadata) {
+		m.ETag = etag
+	}
+}
+
+// WithExpires returns function for setting expiry time of entry (overrides ttl of Set)
+func WithExpires(t time.Time) EntryOptFunc {
+	return func(m *Metadata) {
+		m.Expires = t
+	}
+}
+
+// Get returns data and metadata of entry for key. Missing or expired entry returns ErrNotFound.
+// It waits while other process (or goroutine) is writing the entry.
+// Access time for LRU eviction and Prune is recorded at most once a minute per entry.
+func (s *Store) Get(key string) ([]byte, *Metadata, error) {
+	unlock, err := s.lockEntry(key, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlock()
+	return s.get(key)
+}
+
+// Stat returns metadata of entry for key. Missing or expired entry returns ErrNotFound.
+func (s *Store) Stat(key string) (*Metadata, error) {
+	unlock, err := s.lockEntry(key, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	meta, _, err := s.load(key, false)
+	if err != nil {
+		return nil, err
+	}
+	if meta.Expired(s.now()) {
+		return nil, fmt.Errorf("%w: %s (expired)", ErrNotFound, key)
+	}
+	return meta, nil
+}
+
+// Set stores data for key. The entry expires after ttl (never expires if ttl is not positive).
+// The entry file is replaced atomically under exclusive lock, so concurrent readers never see torn data.
+// If limits of size or number of entries are set, least recently used entries are evicted.
+func (s *Store) Set(key string, data []byte, ttl time.Duration, opts ...EntryOptFunc) error {
+	unlock, err := s.lockEntry(key, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	_, err = s.set(key, data, ttl, opts)
+	return err
+}
+
+// Fill returns entry for key like Get. If the entry is missing or expired, fill is called to compute data,
+// which is stored with ttl and returned. Only one caller across processes computes a missing entry at a time;
+// the others wait for it and return the stored result (single-flight).
+func (s *Store) Fill(key string, ttl time.Duration, fill func() ([]byte, error), opts ...EntryOptFunc) ([]byte, *Metadata, error) {
+	return s.GetOrCompute(context.Background(), key, ttl, func(context.Context) ([]byte, error) { return fill() }, opts...)
+}
+
+// Delete removes entry for key. Deleting missing entry is not error.
+func (s *Store) Delete(key string) error {
+	unlock, err := s.lockEntry(key, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return s.touch(entryName(key), true)
+}
+
+// get is Get without lock
+func (s *Store) get(key string) ([]byte, *Metadata, error) {
+	meta, data, err := s.load(key, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	if meta.Expired(s.now()) {
+		return nil, nil, fmt.Errorf("%w: %s (expired)", ErrNotFound, key)
+	}
+	s.access(entryName(key)) // access time is best-effort
+	return data, meta, nil
+}
+
+// set is Set without lock
+func (s *Store) set(key string, data []byte, ttl time.Duration, opts []EntryOptFunc) (*Metadata, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	meta := &Metadata{Key: key, Created: now, Version: s.version, Size: int64(len(data))}
+	if ttl > 0 {
+		meta.Expires = now.Add(ttl)
+	}
+	for _, opt := range opts {
+		opt(meta)
+	}
+	meta.Encoding, data, err = s.compress(data)
+	if err != nil {
+		return nil, err
+	}
+	header, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, len(header)+1+len(data)))
+	buf.Write(header)
+	buf.WriteByte('\n')
+	buf.Write(data)
+	if err := writeFileAtomic(path, buf.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := s.touch(entryName(key), false); err != nil {
+		return nil, err
+	}
+	return meta, s.evict(entryName(key))
+}
+
+// lockEntry locks entry for key, and returns function to unlock.
+// Shared lock for missing entry returns ErrNotFound without creating lock file.
+func (s *Store) lockEntry(key string, exclusive bool) (func(), error) {
+	return s.lockEntryContext(context.Background(), key, exclusive)
+}
+
+// lockEntryContext locks entry for key like lockEntry, but gives up when ctx is done
+func (s *Store) lockEntryContext(ctx context.Context, key string, exclusive bool) (func(), error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	if exclusive {
+		if err := os.MkdirAll(s.dir, 0700); err != nil {
+			return nil, err
+		}
+	} else if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	l, err := lockPathContext(ctx, filepath.Join(s.dir, lockName(entryName(key))), exclusive)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %w", ErrNotFound, err)
+		}
+		return nil, err
+	}
+	return func() { _ = l.Unlock() }, nil
+}
+
+// load reads entry for key regardless of expiry (entry of other version is not found)
+func (s *Store) load(key string, withData bool) (*Metadata, []byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta, data, err := readEntry(path, withData)
+	if err != nil {
+		return nil, nil, err
+	}
+	if meta.Key != key { // collision of hash (or broken entry)
+		return nil, nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if meta.Version != s.version { // written by other version
+		return nil, nil, fmt.Errorf("%w: %s (version %q)", ErrNotFound, key, meta.Version)
+	}
+	if withData {
+		if data, err = decompress(meta.Encoding, data); err != nil {
+			return nil, nil, fmt.Errorf("%w: %w: %s: %w", ErrNotFound, ErrBrokenEntry, key, err)
+		}
+	}
+	return meta, data, nil
+}
+
+// path returns path of entry file for key
+func (s *Store) path(key string) (string, error) {
+	if len(key) == 0 {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.dir, entryName(key)), nil
+}
+
+// entryName returns file name of entry for key (keys may contain any character, e.g. URL)
+func entryName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:]) + entryExt
+}
+
+// readEntry reads entry file: metadata in JSON (one line) followed by data.
+// Broken entry is reported as ErrNotFound (and ErrBrokenEntry).
+func readEntry(path string, withData bool) (*Metadata, []byte, error) {
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, fmt.Errorf("%w: %w", ErrNotFound, err)
+		}
+		return nil, nil, err
+	}
+	defer file.Close() //nolint:errcheck
+	r := bufio.NewReader(file)
+	header, err := r.ReadBytes('\n')
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w: %s", ErrNotFound, ErrBrokenEntry, path)
+	}
+	meta := &Metadata{}
+	if err := json.Unmarshal(header, meta); err != nil {
+		return nil, nil, fmt.Errorf("%w: %w: %s: %w", ErrNotFound, ErrBrokenEntry, path, err)
+	}
+	if meta.Size == 0 && len(meta.Encoding) == 0 { // written before size was recorded: size of data as is
+		if fi, err := file.Stat(); err == nil {
+			meta.Size = fi.Size() - int64(len(header))
+		}
+	}
+	if !withData {
+		return meta, nil, nil
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return meta, data, nil
+}
@@ -0,0 +1,147 @@
+package cache_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goark/gocli/cache"
+)
+
+// testClock is clock for testing expiry
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestStore(t *testing.T, opts ...cache.OptFunc) (*cache.Store, *testClock) {
+	t.Helper()
+	clock := &testClock{now: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)}
+	s, err := cache.NewStore("app", append([]cache.OptFunc{cache.WithDir(t.TempDir()), cache.WithClock(clock.Now)}, opts...)...)
+	if err != nil {
+		t.Fatalf("cache.NewStore() is \"%v\", want nil error.", err)
+	}
+	return s, clock
+}
+
+func TestStore(t *testing.T) {
+	s, clock := newTestStore(t)
+	key := "https://example.com/api?q=1"
+	if err := s.Set(key, []byte("hello"), time.Hour, cache.WithContentType("text/plain"), cache.WithETag(`"abc"`)); err != nil {
+		t.Fatalf("Store.Set() is \"%v\", want nil error.", err)
+	}
+
+	data, meta, err := s.Get(key)
+	if err != nil {
+		t.Fatalf("Store.Get() is \"%v\", want nil error.", err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("Store.Get() = \"%s\", want \"hello\".", data)
+	}
+	if meta.Key != key || meta.ContentType != "text/plain" || meta.ETag != `"abc"` || !meta.Created.Equal(clock.now) || !meta.Expires.Equal(clock.now.Add(time.Hour)) {
+		t.Errorf("Store.Get() metadata = %+v.", meta)
+	}
+
+	clock.Advance(time.Hour)
+	if _, _, err := s.Get(key); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("Store.Get() of expired entry is \"%v\", want \"%v\".", err, cache.ErrNotFound)
+	}
+	if _, err := s.Stat(key); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("Store.Stat() of expired entry is \"%v\", want \"%v\".", err, cache.ErrNotFound)
+	}
+
+	if err := s.Set(key, []byte("world"), 0); err != nil {
+		t.Fatalf("Store.Set() is \"%v\", want nil error.", err)
+	}
+	clock.Advance(24 * 365 * time.Hour)
+	if meta, err := s.Stat(key); err != nil || !meta.Expires.IsZero() {
+		t.Errorf("Store.Stat() = %+v (%v), want entry never expires.", meta, err)
+	}
+
+	if err := s.Delete(key); err != nil {
+		t.Errorf("Store.Delete() is \"%v\", want nil error.", err)
+	}
+	if _, _, err := s.Get(key); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("Store.Get() of deleted entry is \"%v\", want \"%v\".", err, cache.ErrNotFound)
+	}
+	if err := s.Delete(key); err != nil {
+		t.Errorf("Store.Delete() of missing entry is \"%v\", want nil error.", err)
+	}
+}
+
+func TestStoreError(t *testing.T) {
+	if _, err := cache.NewStore("../foo"); !errors.Is(err, cache.ErrInvalidDir) {
+		t.Errorf("cache.NewStore() is \"%v\", want \"%v\".", err, cache.ErrInvalidDir)
+	}
+	s, _ := newTestStore(t)
+	if err := s.Set("", []byte("x"), 0); !errors.Is(err, cache.ErrInvalidKey) {
+		t.Errorf("Store.Set(\"\") is \"%v\", want \"%v\".", err, cache.ErrInvalidKey)
+	}
+
+	if err := s.Set("key", []byte("x"), 0); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(s.Dir(), "*.cache"))
+	if len(files) != 1 {
+		t.Fatalf("entry files = %v, want 1 file.", files)
+	}
+	if err := os.WriteFile(files[0], []byte("broken"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Get("key"); !errors.Is(err, cache.ErrNotFound) || !errors.Is(err, cache.ErrBrokenEntry) {
+		t.Errorf("Store.Get() of broken entry is \"%v\", want \"%v\".", err, cache.ErrBrokenEntry)
+	}
+}
+
+func TestStoreFill(t *testing.T) {
+	s, _ := newTestStore(t)
+	var calls int32
+	fill := func() ([]byte, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		return []byte("computed"), nil
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, _, err := s.Fill("key", time.Hour, fill)
+			if err == nil && string(data) != "computed" {
+				err = fmt.Errorf("data = %q", data)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Store.Fill() is \"%v\", want nil error.", err)
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("fill is called %d times, want 1.", n)
+	}
+
+	errFill := errors.New("fill error")
+	if _, _, err := s.Fill("other", time.Hour, func() ([]byte, error) { return nil, errFill }); !errors.Is(err, errFill) {
+		t.Errorf("Store.Fill() is \"%v\", want \"%v\".", err, errFill)
+	}
+	if _, _, err := s.Get("other"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("Store.Get() after failed fill is \"%v\", want \"%v\".", err, cache.ErrNotFound)
+	}
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Content types of entries stored by typed helpers
+const (
+	ContentTypeJSON = "application/json"
+	ContentTypeGob  = "application/x-gob"
+)
+
+// WithVersion returns function for setting schema version of Store.
+// Entries written with other version are treated as missing, so bumping the version invalidates all older entries.
+func WithVersion(version string) OptFunc {
+	return func(s *Store) {
+		s.version = version
+	}
+}
+
+// Version returns schema version of Store
+func (s *Store) Version() string {
+	return s.version
+}
+
+// SetJSON stores v encoded in JSON for key (see Set)
+func (s *Store) SetJSON(key string, v interface{}, ttl time.Duration, opts ...EntryOptFunc) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.Set(key, data, ttl, append([]EntryOptFunc{WithContentType(ContentTypeJSON)}, opts...)...)
+}
+
+// GetJSON decodes entry for key in JSON into v. Missing, expired or undecodable entry returns ErrNotFound.
+func (s *Store) GetJSON(key string, v interface{}) (*Metadata, error) {
+	data, meta, err := s.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, fmt.Errorf("%w: %w: %s: %w", ErrNotFound, ErrBrokenEntry, key, err)
+	}
+	return meta, nil
+}
+
+// SetGob stores v encoded in gob for key (see Set)
+func (s *Store) SetGob(key string, v interface{}, ttl time.Duration, opts ...EntryOptFunc) error {
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(v); err != nil {
+		return err
+	}
+	return s.Set(key, buf.Bytes(), ttl, append([]EntryOptFunc{WithContentType(ContentTypeGob)}, opts...)...)
+}
+
+// GetGob decodes entry for key in gob into v. Missing, expired or undecodable entry returns ErrNotFound.
+func (s *Store) GetGob(key string, v interface{}) (*Metadata, error) {
+	data, meta, err := s.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(v); err != nil {
+		return nil, fmt.Errorf("%w: %w: %s: %w", ErrNotFound, ErrBrokenEntry, key, err)
+	}
+	return meta, nil
+}
+
+// Get returns value of type T decoded from entry for key in s.
+// The entry is decoded in gob if it is stored by SetGob, or in JSON otherwise.
+// Missing, expired or undecodable entry returns ErrNotFound.
+func Get[T any](s *Store, key string) (T, *Metadata, error) {
+	var v T
+	data, meta, err := s.Get(key)
+	if err != nil {
+		return v, nil, err
+	}
+	if meta.ContentType == ContentTypeGob {
+		err = gob.NewDecoder(bytes.NewReader(data)).Decode(&v)
+	} else {
+		err = json.Unmarshal(data, &v)
+	}
+	if err != nil {
+		var zero T
+		return zero, nil, fmt.Errorf("%w: %w: %s: %w", ErrNotFound, ErrBrokenEntry, key, err)
+	}
+	return v, meta, nil
+}
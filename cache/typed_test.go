@@ -0,0 +1,74 @@
+package cache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/goark/gocli/cache"
+)
+
+type testRecord struct {
+	Name  string
+	Count int
+}
+
+func TestTypedEntry(t *testing.T) {
+	s, _ := newTestStore(t)
+	want := testRecord{Name: "gocli", Count: 3}
+	if err := s.SetJSON("json", want, time.Hour); err != nil {
+		t.Fatalf("Store.SetJSON() is \"%v\", want nil error.", err)
+	}
+	if err := s.SetGob("gob", want, time.Hour); err != nil {
+		t.Fatalf("Store.SetGob() is \"%v\", want nil error.", err)
+	}
+
+	var got testRecord
+	if meta, err := s.GetJSON("json", &got); err != nil || got != want || meta.ContentType != cache.ContentTypeJSON {
+		t.Errorf("Store.GetJSON() = %+v, %+v (%v), want %+v.", got, meta, err, want)
+	}
+	got = testRecord{}
+	if meta, err := s.GetGob("gob", &got); err != nil || got != want || meta.ContentType != cache.ContentTypeGob {
+		t.Errorf("Store.GetGob() = %+v, %+v (%v), want %+v.", got, meta, err, want)
+	}
+	for _, key := range []string{"json", "gob"} {
+		if got, _, err := cache.Get[testRecord](s, key); err != nil || got != want {
+			t.Errorf("cache.Get(\"%v\") = %+v (%v), want %+v.", key, got, err, want)
+		}
+	}
+
+	if err := s.Set("broken", []byte("{"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := cache.Get[testRecord](s, "broken"); !errors.Is(err, cache.ErrNotFound) || !errors.Is(err, cache.ErrBrokenEntry) {
+		t.Errorf("cache.Get() of broken entry is \"%v\", want \"%v\".", err, cache.ErrBrokenEntry)
+	}
+}
+
+func TestStoreVersion(t *testing.T) {
+	dir := t.TempDir()
+	v1, err := cache.NewStore("app", cache.WithDir(dir), cache.WithVersion("v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v1.SetJSON("key", testRecord{Name: "old"}, 0); err != nil {
+		t.Fatalf("Store.SetJSON() is \"%v\", want nil error.", err)
+	}
+	if _, _, err := cache.Get[testRecord](v1, "key"); err != nil {
+		t.Errorf("cache.Get() of the same version is \"%v\", want nil error.", err)
+	}
+
+	v2, err := cache.NewStore("app", cache.WithDir(dir), cache.WithVersion("v2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := cache.Get[testRecord](v2, "key"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("cache.Get() of older version is \"%v\", want \"%v\".", err, cache.ErrNotFound)
+	}
+	if err := v2.SetJSON("key", testRecord{Name: "new"}, 0); err != nil {
+		t.Fatalf("Store.SetJSON() is \"%v\", want nil error.", err)
+	}
+	if got, meta, err := cache.Get[testRecord](v2, "key"); err != nil || got.Name != "new" || meta.Version != "v2" {
+		t.Errorf("cache.Get() = %+v, %+v (%v), want new entry.", got, meta, err)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/goark/gocli/internal/envdir"
 )
 
 //Path returns path of Configuration file
@@ -22,11 +24,15 @@ func Path(appName, fileName string) string {
 	return filepath.Join(dir, fileName)
 }
 
-//Dir returns Configuration directory
+//Dir returns Configuration directory.
+//Environment variable <PREFIX>CONFIG_DIR (e.g. MYAPP_CONFIG_DIR for "myapp") overrides it.
 func Dir(appName string) string {
 	if includeSlash(appName) {
 		return ""
 	}
+	if dir := envdir.Lookup(appName, "config"); len(dir) > 0 {
+		return dir
+	}
 	dir, err := os.UserConfigDir()
 	if err != nil || len(dir) == 0 {
 		dir = ""
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goark/gocli/internal/envdir"
+)
+
+// EnvError is error type for converting value of environment variable
+type EnvError struct {
+	Name  string
+	Value string
+	Err   error
+}
+
+// Error method for error interface
+func (e *EnvError) Error() string {
+	return fmt.Sprintf("invalid value %q in environment variable %s: %v", e.Value, e.Name, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *EnvError) Unwrap() error {
+	return e.Err
+}
+
+// EnvPrefix returns prefix of environment variables for appName (e.g. "my-app" -> "MY_APP_")
+func EnvPrefix(appName string) string {
+	return envdir.Prefix(appName)
+}
+
+// BindEnv sets fields of struct pointed by v from environment variables.
+//
+// The variable name of field is EnvPrefix(appName) and the key path of the field joined with "_"
+// in upper case (e.g. field "host" in table "db" -> MYAPP_DB_HOST).
+// The name can be given explicitly by `env:"NAME"` tag, and `env:"-"` skips the field.
+// Supported types are string, bool, integers, floats, time.Duration, encoding.TextUnmarshaler
+// and slices of them (comma-separated). Conversion errors of all variables are returned together as *EnvError.
+func BindEnv(appName string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot bind environment variables to %T: not a pointer to struct", v)
+	}
+	errs := []error{}
+	for _, f := range envFields(rv.Elem().Type(), strings.TrimSuffix(EnvPrefix(appName), "_")) {
+		value, ok := os.LookupEnv(f.name)
+		if !ok {
+			continue
+		}
+		if err := setString(fieldByIndex(rv.Elem(), f.index), value); err != nil {
+			errs = append(errs, &EnvError{Name: f.name, Value: value, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// envField is field of struct bound to environment variable
+type envField struct {
+	name  string // name of environment variable
+	key   string // dotted key path of field (e.g. "db.max_conns")
+	index []int  // index sequence of field for reflect.Value.FieldByIndex
+}
+
+// envFields returns fields of struct type t bound to environment variables with prefix (without trailing "_")
+func envFields(t reflect.Type, prefix string) []envField {
+	return appendEnvFields(nil, t, prefix, "", nil)
+}
+
+func appendEnvFields(fields []envField, t reflect.Type, prefix, path string, index []int) []envField {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, ok := fieldKey(f)
+		tag := f.Tag.Get("env")
+		if !ok || tag == "-" {
+			continue
+		}
+		name := tag
+		if len(name) == 0 {
+			name = envName(key)
+			if len(prefix) > 0 {
+				name = prefix + "_" + name
+			}
+		}
+		fpath := joinKey(path, key)
+		findex := append(append([]int{}, index...), i)
+		if isNested(f.Type) {
+			if f.Anonymous && len(tag) == 0 {
+				name = prefix
+			}
+			if f.Anonymous && len(f.Tag.Get("json")) == 0 { // fields are promoted by encoding/json
+				fpath = path
+			}
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			fields = appendEnvFields(fields, ft, name, fpath, findex)
+			continue
+		}
+		fields = append(fields, envField{name: name, key: fpath, index: findex})
+	}
+	return fields
+}
+
+// fieldByIndex returns nested field of struct v like reflect.Value.FieldByIndex, allocating nil pointers on the way
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// envName converts key to name of environment variable
+func envName(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// isNested reports whether t is (pointer to) struct which is bound field by field
+func isNested(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// setString sets value converted from string s to rv
+func setString(rv reflect.Value, s string) error {
+	if rv.Kind() == reflect.Pointer {
+		nv := reflect.New(rv.Type().Elem())
+		if err := setString(nv.Elem(), s); err != nil {
+			return err
+		}
+		rv.Set(nv)
+		return nil
+	}
+	if rv.CanAddr() && rv.Addr().Type().Implements(textUnmarshalerType) {
+		return rv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if rv.Type() == durationType {
+		d, err := time.ParseDuration(strings.TrimSpace(s))
+		if err != nil {
+			return err
+		}
+		rv.SetInt(int64(d))
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return err
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(s), 0, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(strings.TrimSpace(s), 0, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(strings.TrimSpace(s), rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(n)
+	case reflect.Slice:
+		elems := []string{}
+		if len(strings.TrimSpace(s)) > 0 {
+			elems = strings.Split(s, ",")
+		}
+		sv := reflect.MakeSlice(rv.Type(), len(elems), len(elems))
+		for i, elem := range elems {
+			if err := setString(sv.Index(i), strings.TrimSpace(elem)); err != nil {
+				return err
+			}
+		}
+		rv.Set(sv)
+	default:
+		return fmt.Errorf("unsupported type %v", rv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package config_test
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/goark/gocli/config"
+)
+
+func TestEnvPrefix(t *testing.T) {
+	testCases := []struct {
+		appName string
+		prefix  string
+	}{
+		{appName: "myapp", prefix: "MYAPP_"},
+		{appName: "my-app.v2", prefix: "MY_APP_V2_"},
+		{appName: "", prefix: ""},
+	}
+	for _, tc := range testCases {
+		if prefix := config.EnvPrefix(tc.appName); prefix != tc.prefix {
+			t.Errorf("config.EnvPrefix(\"%v\") = \"%v\", want \"%v\".", tc.appName, prefix, tc.prefix)
+		}
+	}
+}
+
+func TestBindEnv(t *testing.T) {
+	type dbConfig struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	var cfg struct {
+		Debug   bool          `json:"debug"`
+		Timeout time.Duration `json:"timeout"`
+		Ratio   float64       `json:"ratio"`
+		Tags    []string      `json:"tags"`
+		Ports   []uint16      `json:"ports"`
+		Token   string        `env:"API_TOKEN"`
+		Addr    net.IP        `json:"addr"`
+		Ignored string        `env:"-"`
+		DB      dbConfig      `json:"db"`
+		Cache   *dbConfig     `json:"cache"`
+		Proxy   *dbConfig     `json:"proxy"`
+	}
+	t.Setenv("MY_APP_DEBUG", "true")
+	t.Setenv("MY_APP_TIMEOUT", "1m30s")
+	t.Setenv("MY_APP_RATIO", "0.5")
+	t.Setenv("MY_APP_TAGS", "a, b,c")
+	t.Setenv("MY_APP_PORTS", "80,443")
+	t.Setenv("API_TOKEN", "secret")
+	t.Setenv("MY_APP_ADDR", "127.0.0.1")
+	t.Setenv("MY_APP_IGNORED", "value")
+	t.Setenv("MY_APP_DB_HOST", "localhost")
+	t.Setenv("MY_APP_DB_PORT", "5432")
+	t.Setenv("MY_APP_CACHE_PORT", "6379")
+
+	if err := config.BindEnv("my-app", &cfg); err != nil {
+		t.Fatalf("config.BindEnv() is \"%v\", want nil error.", err)
+	}
+	if !cfg.Debug || cfg.Timeout != 90*time.Second || cfg.Ratio != 0.5 || cfg.Token != "secret" || cfg.Ignored != "" {
+		t.Errorf("config.BindEnv() = %+v.", cfg)
+	}
+	if !reflect.DeepEqual(cfg.Tags, []string{"a", "b", "c"}) || !reflect.DeepEqual(cfg.Ports, []uint16{80, 443}) {
+		t.Errorf("config.BindEnv() slices = %v, %v.", cfg.Tags, cfg.Ports)
+	}
+	if !cfg.Addr.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Errorf("config.BindEnv() addr = %v, want 127.0.0.1.", cfg.Addr)
+	}
+	if cfg.DB.Host != "localhost" || cfg.DB.Port != 5432 {
+		t.Errorf("config.BindEnv() db = %+v.", cfg.DB)
+	}
+	if cfg.Cache == nil || cfg.Cache.Port != 6379 || cfg.Proxy != nil {
+		t.Errorf("config.BindEnv() cache = %+v, proxy = %+v.", cfg.Cache, cfg.Proxy)
+	}
+}
+
+func TestBindEnvError(t *testing.T) {
+	var cfg struct {
+		Port    int           `json:"port"`
+		Timeout time.Duration `json:"timeout"`
+	}
+	t.Setenv("MYAPP_PORT", "eighty")
+	t.Setenv("MYAPP_TIMEOUT", "10")
+
+	err := config.BindEnv("myapp", &cfg)
+	names := map[string]bool{}
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var ee *config.EnvError
+		if errors.As(e, &ee) {
+			names[ee.Name] = true
+		}
+	}
+	if !names["MYAPP_PORT"] || !names["MYAPP_TIMEOUT"] {
+		t.Errorf("config.BindEnv() is \"%v\", want errors of MYAPP_PORT and MYAPP_TIMEOUT.", err)
+	}
+	if err := config.BindEnv("myapp", cfg); err == nil {
+		t.Error("config.BindEnv(struct) is nil, want error.")
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// fieldKey returns configuration key of struct field (name in json tag or field name)
+func fieldKey(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return f.Name, true
+	}
+	return name, true
+}
+
+// fieldByKey returns struct field for configuration key (case-insensitive, like encoding/json)
+func fieldByKey(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Tag.Get("json") == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if ef, ok := fieldByKey(ft, key); ok {
+					return ef, true
+				}
+				continue
+			}
+		}
+		if name, ok := fieldKey(f); ok && strings.EqualFold(name, key) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
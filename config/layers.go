@@ -0,0 +1,426 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SourceKind is kind of configuration source
+type SourceKind int
+
+// Kinds of configuration source
+const (
+	FromValue SourceKind = iota
+	FromFile
+	FromEnv
+	FromCommandLine
+)
+
+// Origin is provenance of configuration value
+type Origin struct {
+	Kind SourceKind
+	Name string //file path, environment variable name or command-line option name
+	Line int    //line number in file (zero if unknown)
+}
+
+// String is Stringer method
+func (o Origin) String() string {
+	switch o.Kind {
+	case FromFile:
+		if o.Line > 0 {
+			return fmt.Sprintf("%s:%d", o.Name, o.Line)
+		}
+		return o.Name
+	case FromEnv:
+		return "environment variable " + o.Name
+	case FromCommandLine:
+		return "command-line option " + o.Name
+	}
+	if len(o.Name) > 0 {
+		return o.Name
+	}
+	return "value"
+}
+
+// KeyLocator is optional interface of Decoder to report line numbers of keys.
+// Keys of nested tables are joined with dot (e.g. "db.host").
+type KeyLocator interface {
+	Locate(data []byte) map[string]int
+}
+
+// Layers is layered configuration. Sources added later override earlier ones,
+// nested tables are merged deeply, and the origin of every value is recorded.
+type Layers struct {
+	values  map[string]interface{}
+	origins map[string]Origin
+	orders  map[string]int // order of source of each leaf value (later source has larger order)
+	order   int
+	envs    []layerEnv
+}
+
+// layerEnv is environment variable merged by AddEnv
+type layerEnv struct {
+	prefix string
+	name   string
+	value  string
+	key    string // key mapped from name without target struct
+	order  int
+}
+
+// NewLayers returns a new Layers instance
+func NewLayers() *Layers {
+	return &Layers{values: map[string]interface{}{}, origins: map[string]Origin{}, orders: map[string]int{}}
+}
+
+// AddFile merges configuration file at path
+func (l *Layers) AddFile(path string) error {
+	m, lines, err := readMap(path)
+	if err != nil {
+		return err
+	}
+	l.order++
+	l.merge(l.values, m, "", Origin{Kind: FromFile, Name: path}, lines)
+	return nil
+}
+
+// readMap decodes configuration file at path into map, and returns it with line numbers of keys
+func readMap(path string) (map[string]interface{}, map[string]int, error) {
+	dec, ok := DecoderFor(path)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNoDecoder, path)
+	}
+	data, err := readFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	m := map[string]interface{}{}
+	if err := dec.Decode(data, &m); err != nil {
+		return nil, nil, parseError(path, data, err)
+	}
+	var lines map[string]int
+	if loc, ok := dec.(KeyLocator); ok {
+		lines = loc.Locate(data)
+	}
+	return normalizeMap(m), lines, nil
+}
+
+// AddSearchPaths merges all existing configuration files returned by Find(appName, fileName) in precedence order
+func (l *Layers) AddSearchPaths(appName, fileName string) error {
+	for _, loc := range Find(appName, fileName) {
+		if !loc.Exists {
+			continue
+		}
+		if err := l.AddFile(loc.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddMap merges map m
+func (l *Layers) AddMap(m map[string]interface{}, origin Origin) {
+	l.order++
+	l.merge(l.values, normalizeMap(m), "", origin, nil)
+}
+
+// AddEnv merges environment variables with prefix (see EnvPrefix).
+// The name is mapped to key by removing prefix, lower-casing and replacing "_" with "." (e.g. MYAPP_DB_HOST -> db.host with prefix "MYAPP_").
+// Decode into struct maps the names to fields in the same way as BindEnv instead,
+// so key containing "_" is also decoded (e.g. MYAPP_DB_MAX_CONNS -> field of key "max_conns" in table "db").
+func (l *Layers) AddEnv(prefix string) {
+	if len(prefix) == 0 {
+		return
+	}
+	for _, kv := range os.Environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+			continue
+		}
+		key := strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(name, prefix)), "_", ".")
+		l.Set(key, value, Origin{Kind: FromEnv, Name: name})
+		l.envs = append(l.envs, layerEnv{prefix: prefix, name: name, value: value, key: key, order: l.order})
+	}
+}
+
+// Set sets value for dotted key (e.g. "db.host")
+func (l *Layers) Set(key string, value interface{}, origin Origin) {
+	parts := strings.Split(key, ".")
+	for i := len(parts) - 1; i > 0; i-- {
+		value = map[string]interface{}{parts[i]: value}
+	}
+	l.order++
+	l.merge(l.values, map[string]interface{}{parts[0]: value}, "", origin, nil)
+}
+
+// Get returns value for dotted key
+func (l *Layers) Get(key string) (interface{}, bool) {
+	var value interface{} = l.values
+	for _, part := range strings.Split(key, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// Origin returns where the value for dotted key came from.
+// Only keys of leaf values (not tables) have origin.
+func (l *Layers) Origin(key string) (Origin, bool) {
+	o, ok := l.origins[key]
+	return o, ok
+}
+
+// Keys returns sorted dotted keys of all leaf values
+func (l *Layers) Keys() []string {
+	keys := make([]string, 0, len(l.origins))
+	for key := range l.origins {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Map returns copy of merged configuration
+func (l *Layers) Map() map[string]interface{} {
+	return copyValue(l.values).(map[string]interface{})
+}
+
+// Decode decodes merged configuration into v (pointer to struct or map).
+// String values (e.g. from environment variables) are converted to the type of destination field.
+// Default values and validation are applied as LoadFile.
+func (l *Layers) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("cannot decode into %T: not a pointer", v)
+	}
+	values := l.values
+	if t := rv.Type().Elem(); t.Kind() == reflect.Struct && len(l.envs) > 0 {
+		values = l.resolveEnv(t)
+	}
+	data, err := json.Marshal(coerce(values, rv.Type().Elem()))
+	if err != nil {
+		return err
+	}
+	if err := decodeDefaults(v, func() error {
+		err := json.Unmarshal(data, v)
+		var te *json.UnmarshalTypeError
+		if errors.As(err, &te) {
+			if o, ok := l.origins[te.Field]; ok {
+				return fmt.Errorf("%w (from %v)", err, o)
+			}
+		}
+		return err
+	}); err != nil {
+		return err
+	}
+	return Validate(v)
+}
+
+func (l *Layers) merge(dst, src map[string]interface{}, prefix string, origin Origin, lines map[string]int) {
+	for k, sv := range src {
+		key := joinKey(prefix, k)
+		if sm, ok := sv.(map[string]interface{}); ok {
+			dm, ok := dst[k].(map[string]interface{})
+			if !ok {
+				delete(l.origins, key)
+				delete(l.orders, key)
+				dm = map[string]interface{}{}
+				dst[k] = dm
+			}
+			l.merge(dm, sm, key, origin, lines)
+			continue
+		}
+		if _, ok := dst[k].(map[string]interface{}); ok {
+			for sub := range l.origins {
+				if strings.HasPrefix(sub, key+".") {
+					delete(l.origins, sub)
+					delete(l.orders, sub)
+				}
+			}
+		}
+		dst[k] = copyValue(sv)
+		o := origin
+		if line, ok := lines[key]; ok {
+			o.Line = line
+		}
+		l.origins[key] = o
+		l.orders[key] = l.order
+	}
+}
+
+// resolveEnv returns copy of merged configuration with keys from environment variables (see AddEnv)
+// mapped to fields of struct type t in the same way as BindEnv, unless overridden by later sources.
+func (l *Layers) resolveEnv(t reflect.Type) map[string]interface{} {
+	values := copyValue(l.values).(map[string]interface{})
+	for _, e := range l.envs {
+		if l.orders[e.key] != e.order || l.origins[e.key] != (Origin{Kind: FromEnv, Name: e.name}) {
+			continue // overridden
+		}
+		for _, f := range envFields(t, strings.TrimSuffix(e.prefix, "_")) {
+			if f.name != e.name || strings.EqualFold(f.key, e.key) {
+				continue
+			}
+			deleteKey(values, strings.Split(e.key, "."))
+			if order, ok := l.orders[f.key]; !ok || order < e.order {
+				setKey(values, strings.Split(f.key, "."), e.value)
+			}
+			break
+		}
+	}
+	return values
+}
+
+// deleteKey deletes value for key path in m, and removes tables left empty
+func deleteKey(m map[string]interface{}, parts []string) {
+	if len(parts) > 1 {
+		if sub, ok := m[parts[0]].(map[string]interface{}); ok {
+			deleteKey(sub, parts[1:])
+			if len(sub) > 0 {
+				return
+			}
+		} else {
+			return
+		}
+	}
+	delete(m, parts[0])
+}
+
+// setKey sets value for key path in m, creating (or replacing non-table values with) tables on the way
+func setKey(m map[string]interface{}, parts []string, value interface{}) {
+	for _, part := range parts[:len(parts)-1] {
+		sub, ok := m[part].(map[string]interface{})
+		if !ok {
+			sub = map[string]interface{}{}
+			m[part] = sub
+		}
+		m = sub
+	}
+	m[parts[len(parts)-1]] = value
+}
+
+func joinKey(prefix, key string) string {
+	if len(prefix) == 0 {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// normalizeMap converts map[interface{}]interface{} (some YAML decoders) to map[string]interface{}
+func normalizeMap(m map[string]interface{}) map[string]interface{} {
+	return normalizeValue(m).(map[string]interface{})
+}
+
+func normalizeValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			m[k] = normalizeValue(e)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			m[fmt.Sprint(k)] = normalizeValue(e)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(t))
+		for i, e := range t {
+			s[i] = normalizeValue(e)
+		}
+		return s
+	}
+	return v
+}
+
+func copyValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			m[k] = copyValue(e)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(t))
+		for i, e := range t {
+			s[i] = copyValue(e)
+		}
+		return s
+	}
+	return v
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// coerce converts string values in v to the kind of type t as far as possible
+func coerce(v interface{}, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch value := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, e := range value {
+			switch t.Kind() {
+			case reflect.Struct:
+				if f, ok := fieldByKey(t, k); ok {
+					m[k] = coerce(e, f.Type)
+					continue
+				}
+			case reflect.Map:
+				m[k] = coerce(e, t.Elem())
+				continue
+			}
+			m[k] = e
+		}
+		return m
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return v
+		}
+		s := make([]interface{}, len(value))
+		for i, e := range value {
+			s[i] = coerce(e, t.Elem())
+		}
+		return s
+	case string:
+		return coerceString(value, t)
+	}
+	return v
+}
+
+func coerceString(s string, t reflect.Type) interface{} {
+	if t == durationType {
+		if d, err := time.ParseDuration(s); err == nil {
+			return int64(d)
+		}
+		return s
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		n := json.Number(strings.TrimSpace(s))
+		if _, err := n.Float64(); err == nil && json.Valid([]byte(n)) {
+			return n
+		}
+	}
+	return s
+}
@@ -0,0 +1,128 @@
+package config_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/goark/gocli/config"
+)
+
+func TestLayers(t *testing.T) {
+	dir := t.TempDir()
+	system := filepath.Join(dir, "system.json")
+	user := filepath.Join(dir, "user.json")
+	writeFile(t, system, "{\n  \"name\": \"sys\",\n  \"db\": {\n    \"host\": \"db.example.com\",\n    \"port\": 5432\n  },\n  \"tags\": [\"a\"]\n}\n")
+	writeFile(t, user, "{\n  \"db\": {\n    \"port\": 15432\n  }\n}\n")
+	t.Setenv("GOCLITEST_DB_USER", "alice")
+	t.Setenv("GOCLITEST_TIMEOUT", "3s")
+
+	l := config.NewLayers()
+	for _, path := range []string{system, user} {
+		if err := l.AddFile(path); err != nil {
+			t.Fatalf("Layers.AddFile() is \"%v\", want nil error.", err)
+		}
+	}
+	l.AddEnv("GOCLITEST_")
+	l.Set("name", "cli", config.Origin{Kind: config.FromCommandLine, Name: "--name"})
+
+	testCases := []struct {
+		key    string
+		value  interface{}
+		origin string
+	}{
+		{key: "name", value: "cli", origin: "command-line option --name"},
+		{key: "db.host", value: "db.example.com", origin: system + ":4"},
+		{key: "db.port", value: float64(15432), origin: user + ":3"},
+		{key: "db.user", value: "alice", origin: "environment variable GOCLITEST_DB_USER"},
+		{key: "tags", value: []interface{}{"a"}, origin: system + ":7"},
+	}
+	for _, tc := range testCases {
+		if v, ok := l.Get(tc.key); !ok || !reflect.DeepEqual(v, tc.value) {
+			t.Errorf("Layers.Get(\"%v\") = %v, want %v.", tc.key, v, tc.value)
+		}
+		if o, ok := l.Origin(tc.key); !ok || o.String() != tc.origin {
+			t.Errorf("Layers.Origin(\"%v\") = \"%v\", want \"%v\".", tc.key, o, tc.origin)
+		}
+	}
+	if _, ok := l.Origin("db"); ok {
+		t.Error("Layers.Origin(\"db\") is found, want not found.")
+	}
+
+	var cfg struct {
+		Name string `json:"name"`
+		DB   struct {
+			Host string
+			Port int
+			User string
+		} `json:"db"`
+		Timeout time.Duration `json:"timeout"`
+	}
+	if err := l.Decode(&cfg); err != nil {
+		t.Fatalf("Layers.Decode() is \"%v\", want nil error.", err)
+	}
+	if cfg.Name != "cli" || cfg.DB.Host != "db.example.com" || cfg.DB.Port != 15432 || cfg.DB.User != "alice" || cfg.Timeout != 3*time.Second {
+		t.Errorf("Layers.Decode() = %+v.", cfg)
+	}
+}
+
+func TestLayersReplaceTable(t *testing.T) {
+	l := config.NewLayers()
+	l.AddMap(map[string]interface{}{"db": map[string]interface{}{"host": "localhost"}}, config.Origin{Name: "defaults"})
+	l.Set("db", "sqlite", config.Origin{Kind: config.FromCommandLine, Name: "--db"})
+
+	if keys := l.Keys(); !reflect.DeepEqual(keys, []string{"db"}) {
+		t.Errorf("Layers.Keys() = %v, want [db].", keys)
+	}
+	l.Set("db.host", "example.com", config.Origin{Kind: config.FromCommandLine, Name: "--db.host"})
+	if keys := l.Keys(); !reflect.DeepEqual(keys, []string{"db.host"}) {
+		t.Errorf("Layers.Keys() = %v, want [db.host].", keys)
+	}
+}
+
+func TestLayersEnvUnderscore(t *testing.T) {
+	type layersConfig struct {
+		DB struct {
+			Host     string `json:"host"`
+			MaxConns int    `json:"max_conns" default:"10"`
+		} `json:"db"`
+		LogLevel string `json:"log_level"`
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"db": {"host": "localhost", "max_conns": 5}}`)
+	t.Setenv("GOCLILAYERS_DB_MAX_CONNS", "42")
+	t.Setenv("GOCLILAYERS_LOG_LEVEL", "debug")
+
+	var bound layersConfig
+	if err := config.BindEnv("goclilayers", &bound); err != nil {
+		t.Fatalf("config.BindEnv() is \"%v\", want nil error.", err)
+	}
+	testCases := []struct {
+		set      bool
+		maxConns int
+	}{
+		{set: false, maxConns: bound.DB.MaxConns},
+		{set: true, maxConns: 7}, // command-line option added later wins
+	}
+	for _, tc := range testCases {
+		l := config.NewLayers()
+		if err := l.AddFile(path); err != nil {
+			t.Fatalf("Layers.AddFile() is \"%v\", want nil error.", err)
+		}
+		l.AddEnv(config.EnvPrefix("goclilayers"))
+		if tc.set {
+			l.Set("db.max_conns", 7, config.Origin{Kind: config.FromCommandLine, Name: "--max-conns"})
+		}
+		var cfg layersConfig
+		if err := l.Decode(&cfg); err != nil {
+			t.Fatalf("Layers.Decode() is \"%v\", want nil error.", err)
+		}
+		if cfg.DB.MaxConns != tc.maxConns || cfg.DB.Host != "localhost" || cfg.LogLevel != bound.LogLevel {
+			t.Errorf("Layers.Decode() = %+v, want max_conns %d and log_level %q.", cfg, tc.maxConns, bound.LogLevel)
+		}
+	}
+	if bound.DB.MaxConns != 42 || bound.LogLevel != "debug" {
+		t.Errorf("config.BindEnv() = %+v.", bound)
+	}
+}
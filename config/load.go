@@ -0,0 +1,228 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+//Errors in loading configuration file
+var (
+	ErrInvalidPath = errors.New("invalid path of configuration file")
+	ErrNotFound    = errors.New("configuration file not found")
+	ErrPermission  = errors.New("permission denied for configuration file")
+	ErrNoDecoder   = errors.New("no decoder for configuration file")
+)
+
+//ParseError is error type for syntax or type error in configuration file.
+//Line and Column are 1-based, and zero if the position is unknown.
+type ParseError struct {
+	Path   string
+	Line   int
+	Column int
+	Err    error
+}
+
+//Error method for error interface
+func (e *ParseError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("cannot parse %s:%d:%d: %v", e.Path, e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("cannot parse %s: %v", e.Path, e.Err)
+}
+
+//Unwrap returns the underlying error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+//Decoder is interface for decoding configuration data into value v.
+//Decode may return *ParseError to report the position of error.
+type Decoder interface {
+	Decode(data []byte, v interface{}) error
+}
+
+//DecoderFunc is adapter to use ordinary function as Decoder
+type DecoderFunc func(data []byte, v interface{}) error
+
+//Decode calls f(data, v)
+func (f DecoderFunc) Decode(data []byte, v interface{}) error {
+	return f(data, v)
+}
+
+var (
+	decoderMutex sync.RWMutex
+	decoderMap   = map[string]Decoder{".json": jsonDecoder{}}
+)
+
+//jsonDecoder is Decoder for JSON format
+type jsonDecoder struct{}
+
+//Decode calls json.Unmarshal()
+func (jsonDecoder) Decode(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+//Locate returns line numbers of keys in JSON data (KeyLocator interface)
+func (jsonDecoder) Locate(data []byte) map[string]int {
+	lines := map[string]int{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var walk func(prefix string, record bool) error
+	walk = func(prefix string, record bool) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				tok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key := joinKey(prefix, fmt.Sprint(tok))
+				if record {
+					lines[key], _ = position(data, dec.InputOffset())
+				}
+				if err := walk(key, record); err != nil {
+					return err
+				}
+			}
+		case json.Delim('['):
+			for dec.More() {
+				if err := walk(prefix, false); err != nil { // elements of array are not recorded
+					return err
+				}
+			}
+		default:
+			return nil
+		}
+		_, err = dec.Token() // closing delimiter
+		return err
+	}
+	_ = walk("", true)
+	return lines
+}
+
+//RegisterDecoder registers Decoder for file extension (e.g. ".toml", ".yaml").
+//JSON decoder is registered by default. If dec is nil, the decoder for ext is removed.
+func RegisterDecoder(ext string, dec Decoder) {
+	ext = normalizeExt(ext)
+	decoderMutex.Lock()
+	defer decoderMutex.Unlock()
+	if dec == nil {
+		delete(decoderMap, ext)
+		return
+	}
+	decoderMap[ext] = dec
+}
+
+//DecoderFor returns Decoder registered for extension of path
+func DecoderFor(path string) (Decoder, bool) {
+	decoderMutex.RLock()
+	defer decoderMutex.RUnlock()
+	dec, ok := decoderMap[normalizeExt(filepath.Ext(path))]
+	return dec, ok
+}
+
+//Load decodes configuration file at Path(appName, fileName) into v.
+//Values already set in v are kept unless the file overrides them, so v can be initialized with defaults before calling Load.
+func Load(appName, fileName string, v interface{}) error {
+	path := Path(appName, fileName)
+	if len(path) == 0 {
+		return fmt.Errorf("%w: app %q, file %q", ErrInvalidPath, appName, fileName)
+	}
+	return LoadFile(path, v)
+}
+
+//LoadFile decodes configuration file at path into v.
+//The decoder is selected by extension of path.
+//If v is a pointer to struct, `default` tags fill zero fields before decoding (even if the file is not found),
+//so zero values in the file are kept. Elements of slices and pointers created from the file get defaults after decoding.
+//Then `validate` tags are checked (see SetDefaults and Validate).
+func LoadFile(path string, v interface{}) error {
+	dec, ok := DecoderFor(path)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNoDecoder, path)
+	}
+	data, err := readFile(path)
+	if err != nil {
+		if derr := SetDefaults(v); derr != nil {
+			return derr
+		}
+		return err
+	}
+	if err := decodeDefaults(v, func() error {
+		if err := dec.Decode(data, v); err != nil {
+			return parseError(path, data, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return Validate(v)
+}
+
+func readFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path) //nolint:gosec
+	switch {
+	case err == nil:
+		return data, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, fmt.Errorf("%w: %w", ErrNotFound, err)
+	case errors.Is(err, fs.ErrPermission):
+		return nil, fmt.Errorf("%w: %w", ErrPermission, err)
+	}
+	return nil, err
+}
+
+func parseError(path string, data []byte, err error) error {
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		if len(pe.Path) == 0 {
+			pe.Path = path
+		}
+		return pe
+	}
+	pe = &ParseError{Path: path, Err: err}
+	var se *json.SyntaxError
+	var te *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &se):
+		pe.Line, pe.Column = position(data, se.Offset)
+	case errors.As(err, &te):
+		pe.Line, pe.Column = position(data, te.Offset)
+	}
+	return pe
+}
+
+//position returns line and column of the byte just before offset in data (1-based)
+func position(data []byte, offset int64) (int, int) {
+	if offset < 0 {
+		return 0, 0
+	}
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	head := data[:offset]
+	line := bytes.Count(head, []byte("\n")) + 1
+	col := len(head) - bytes.LastIndexByte(head, '\n') - 1
+	if col < 1 {
+		col = 1
+	}
+	return line, col
+}
+
+func normalizeExt(ext string) string {
+	ext = strings.ToLower(ext)
+	if len(ext) > 0 && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
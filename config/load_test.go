@@ -0,0 +1,115 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goark/gocli/config"
+)
+
+type testConfig struct {
+	Name string `json:"name"`
+	Port int    `json:"port"`
+	DB   struct {
+		Host string `json:"host"`
+	} `json:"db"`
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"name": "foo", "db": {"host": "localhost"}}`)
+
+	cfg := testConfig{Port: 8080}
+	if err := config.LoadFile(path, &cfg); err != nil {
+		t.Fatalf("config.LoadFile() is \"%v\", want nil error.", err)
+	}
+	if cfg.Name != "foo" || cfg.Port != 8080 || cfg.DB.Host != "localhost" {
+		t.Errorf("config.LoadFile() = %+v, want {foo 8080 {localhost}}.", cfg)
+	}
+}
+
+func TestLoadFileError(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "syntax.json"), "{\n  \"name\": \"foo\",\n  \"port\": x\n}\n")
+	writeFile(t, filepath.Join(dir, "type.json"), "{\n  \"port\": \"80\"\n}\n")
+	writeFile(t, filepath.Join(dir, "config.ini"), "name=foo\n")
+
+	testCases := []struct {
+		fileName string
+		err      error
+		line     int
+		column   int
+	}{
+		{fileName: "none.json", err: config.ErrNotFound},
+		{fileName: "config.ini", err: config.ErrNoDecoder},
+		{fileName: "syntax.json", line: 3, column: 11},
+		{fileName: "type.json", line: 2, column: 14},
+	}
+	for _, tc := range testCases {
+		var cfg testConfig
+		err := config.LoadFile(filepath.Join(dir, tc.fileName), &cfg)
+		if tc.err != nil {
+			if !errors.Is(err, tc.err) {
+				t.Errorf("config.LoadFile(\"%v\") is \"%v\", want \"%v\".", tc.fileName, err, tc.err)
+			}
+			continue
+		}
+		var pe *config.ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("config.LoadFile(\"%v\") is \"%v\", want *config.ParseError.", tc.fileName, err)
+			continue
+		}
+		if pe.Line != tc.line || pe.Column != tc.column {
+			t.Errorf("config.LoadFile(\"%v\") position is %d:%d, want %d:%d.", tc.fileName, pe.Line, pe.Column, tc.line, tc.column)
+		}
+	}
+}
+
+func TestLoadFileNotFound(t *testing.T) {
+	err := config.LoadFile(filepath.Join(t.TempDir(), "none.json"), &testConfig{})
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("config.LoadFile() is \"%v\", want \"%v\".", err, os.ErrNotExist)
+	}
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	config.RegisterDecoder("ini", config.DecoderFunc(func(data []byte, v interface{}) error {
+		cfg := v.(*testConfig)
+		for _, line := range strings.Split(string(data), "\n") {
+			if kv := strings.SplitN(line, "=", 2); len(kv) == 2 && kv[0] == "name" {
+				cfg.Name = kv[1]
+			}
+		}
+		return nil
+	}))
+	defer config.RegisterDecoder(".ini", nil)
+
+	path := filepath.Join(t.TempDir(), "config.INI")
+	writeFile(t, path, "name=bar\n")
+	var cfg testConfig
+	if err := config.LoadFile(path, &cfg); err != nil {
+		t.Fatalf("config.LoadFile() is \"%v\", want nil error.", err)
+	}
+	if cfg.Name != "bar" {
+		t.Errorf("config.LoadFile() name is \"%v\", want \"%v\".", cfg.Name, "bar")
+	}
+}
+
+func TestLoadInvalidPath(t *testing.T) {
+	if err := config.Load("../foo", "config.json", &testConfig{}); !errors.Is(err, config.ErrInvalidPath) {
+		t.Errorf("config.Load() is \"%v\", want \"%v\".", err, config.ErrInvalidPath)
+	}
+}
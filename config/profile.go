@@ -0,0 +1,135 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrProfileNotFound is error for unknown profile
+var ErrProfileNotFound = errors.New("profile not found")
+
+const (
+	// ProfileDir is name of directory for profile files in Dir(appName) (e.g. profiles/staging.toml)
+	ProfileDir = "profiles"
+	// ProfileKey is key of table for profile sections in default file (e.g. [profile.staging])
+	ProfileKey = "profile"
+	// ProfileEnv is suffix of environment variable to select profile (e.g. MYAPP_PROFILE)
+	ProfileEnv = "PROFILE"
+)
+
+// ProfileName returns name of profile: name if it is not empty, otherwise value of environment variable
+// EnvPrefix(appName)+ProfileEnv. Empty string means default profile.
+func ProfileName(appName, name string) string {
+	if len(name) > 0 || len(appName) == 0 {
+		return name
+	}
+	return os.Getenv(EnvPrefix(appName) + ProfileEnv)
+}
+
+// LoadProfile decodes configuration of profile into v (see ProfileLayers).
+func LoadProfile(appName, fileName, profile string, v interface{}) error {
+	l, err := ProfileLayers(appName, fileName, profile)
+	if err != nil {
+		return err
+	}
+	return l.Decode(v)
+}
+
+// ProfileLayers returns configuration of profile selected by ProfileName(appName, profile).
+// The profile inherits default file Path(appName, fileName), and is overridden in order by
+// [profile.<name>] section in default file and profile file <Dir(appName)>/profiles/<name><ext of fileName>.
+func ProfileLayers(appName, fileName, profile string) (*Layers, error) {
+	path := Path(appName, fileName)
+	if len(path) == 0 {
+		return nil, fmt.Errorf("%w: app %q, file %q", ErrInvalidPath, appName, fileName)
+	}
+	profile = ProfileName(appName, profile)
+	if includeSlash(profile) || strings.HasPrefix(profile, ".") {
+		return nil, fmt.Errorf("%w: invalid name %q", ErrProfileNotFound, profile)
+	}
+
+	l := NewLayers()
+	found := false
+	m, lines, err := readMap(path)
+	if err != nil && (!errors.Is(err, ErrNotFound) || len(profile) == 0) {
+		return nil, err
+	}
+	if err == nil {
+		origin := Origin{Kind: FromFile, Name: path}
+		sections, _ := m[ProfileKey].(map[string]interface{})
+		delete(m, ProfileKey)
+		l.merge(l.values, m, "", origin, lines)
+		if len(profile) == 0 {
+			return l, nil
+		}
+		if section, ok := sections[profile].(map[string]interface{}); ok {
+			l.merge(l.values, section, "", origin, subLines(lines, ProfileKey+"."+profile))
+			found = true
+		}
+	}
+	switch err := l.AddFile(profilePath(path, profile)); {
+	case err == nil:
+		found = true
+	case !errors.Is(err, ErrNotFound):
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, profile)
+	}
+	return l, nil
+}
+
+// Profiles returns sorted names of available profiles (sections in default file and profile files).
+func Profiles(appName, fileName string) ([]string, error) {
+	path := Path(appName, fileName)
+	if len(path) == 0 {
+		return nil, fmt.Errorf("%w: app %q, file %q", ErrInvalidPath, appName, fileName)
+	}
+	names := map[string]bool{}
+	m, _, err := readMap(path)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if sections, ok := m[ProfileKey].(map[string]interface{}); ok {
+		for name, section := range sections {
+			if _, ok := section.(map[string]interface{}); ok {
+				names[name] = true
+			}
+		}
+	}
+	ext := filepath.Ext(fileName)
+	entries, err := os.ReadDir(filepath.Join(filepath.Dir(path), ProfileDir))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && strings.EqualFold(filepath.Ext(name), ext) && !strings.HasPrefix(name, ".") {
+			names[strings.TrimSuffix(name, filepath.Ext(name))] = true
+		}
+	}
+	list := make([]string, 0, len(names))
+	for name := range names {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list, nil
+}
+
+func profilePath(path, profile string) string {
+	return filepath.Join(filepath.Dir(path), ProfileDir, profile+filepath.Ext(path))
+}
+
+// subLines returns line numbers of keys under prefix, with the prefix removed
+func subLines(lines map[string]int, prefix string) map[string]int {
+	sub := map[string]int{}
+	for key, line := range lines {
+		if strings.HasPrefix(key, prefix+".") {
+			sub[strings.TrimPrefix(key, prefix+".")] = line
+		}
+	}
+	return sub
+}
@@ -0,0 +1,85 @@
+package config_test
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goark/gocli/config"
+)
+
+func setConfigHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	dir := config.Dir("gocli-test")
+	if !strings.HasPrefix(dir, home) {
+		t.Skip("os.UserConfigDir() does not refer $XDG_CONFIG_HOME on this platform")
+	}
+	return dir
+}
+
+func TestLoadProfile(t *testing.T) {
+	dir := setConfigHome(t)
+	writeFile(t, filepath.Join(dir, "config.json"), `{
+  "name": "default",
+  "db": {"host": "localhost", "port": 5432},
+  "profile": {
+    "staging": {"db": {"host": "staging.example.com"}}
+  }
+}`)
+	writeFile(t, filepath.Join(dir, config.ProfileDir, "production.json"), `{"db": {"host": "db.example.com"}}`)
+	writeFile(t, filepath.Join(dir, config.ProfileDir, "staging.json"), `{"name": "stg"}`)
+
+	type dbConfig struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	type profileConfig struct {
+		Name string   `json:"name"`
+		DB   dbConfig `json:"db"`
+	}
+	testCases := []struct {
+		profile string
+		env     string
+		cfg     profileConfig
+		err     error
+	}{
+		{profile: "", cfg: profileConfig{Name: "default", DB: dbConfig{Host: "localhost", Port: 5432}}},
+		{profile: "staging", cfg: profileConfig{Name: "stg", DB: dbConfig{Host: "staging.example.com", Port: 5432}}},
+		{profile: "", env: "production", cfg: profileConfig{Name: "default", DB: dbConfig{Host: "db.example.com", Port: 5432}}},
+		{profile: "staging", env: "production", cfg: profileConfig{Name: "stg", DB: dbConfig{Host: "staging.example.com", Port: 5432}}},
+		{profile: "develop", err: config.ErrProfileNotFound},
+		{profile: "../config", err: config.ErrProfileNotFound},
+	}
+	for _, tc := range testCases {
+		t.Setenv("GOCLI_TEST_PROFILE", tc.env)
+		var cfg profileConfig
+		err := config.LoadProfile("gocli-test", "config.json", tc.profile, &cfg)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("config.LoadProfile(\"%v\") is \"%v\", want \"%v\".", tc.profile, err, tc.err)
+			continue
+		}
+		if !reflect.DeepEqual(cfg, tc.cfg) {
+			t.Errorf("config.LoadProfile(\"%v\") = %+v, want %+v.", tc.profile, cfg, tc.cfg)
+		}
+	}
+
+	l, err := config.ProfileLayers("gocli-test", "config.json", "staging")
+	if err != nil {
+		t.Fatalf("config.ProfileLayers() is \"%v\", want nil error.", err)
+	}
+	if o, _ := l.Origin("db.host"); o.String() != filepath.Join(dir, "config.json")+":5" {
+		t.Errorf("Layers.Origin(\"db.host\") = %v, want line 5 of default file.", o)
+	}
+	if _, ok := l.Get("profile"); ok {
+		t.Error("Layers.Get(\"profile\") is found, want not found.")
+	}
+
+	profiles, err := config.Profiles("gocli-test", "config.json")
+	if want := []string{"production", "staging"}; err != nil || !reflect.DeepEqual(profiles, want) {
+		t.Errorf("config.Profiles() = %v (%v), want %v.", profiles, err, want)
+	}
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+)
+
+// ErrNoEncoder is error for unknown format of configuration file
+var ErrNoEncoder = errors.New("no encoder for configuration file")
+
+// BackupSuffix is suffix of backup file created by Save
+const BackupSuffix = ".bak"
+
+// Encoder is interface for encoding value v into configuration data
+type Encoder interface {
+	Encode(v interface{}) ([]byte, error)
+}
+
+// EncoderFunc is adapter to use ordinary function as Encoder
+type EncoderFunc func(v interface{}) ([]byte, error)
+
+// Encode calls f(v)
+func (f EncoderFunc) Encode(v interface{}) ([]byte, error) {
+	return f(v)
+}
+
+var (
+	encoderMutex sync.RWMutex
+	encoderMap   = map[string]Encoder{".json": EncoderFunc(encodeSavedJSON)}
+)
+
+// encodeSavedJSON encodes v in indented JSON with actual values of Secret (see Secret.MarshalJSON)
+func encodeSavedJSON(v interface{}) ([]byte, error) {
+	if rv := reflect.ValueOf(v); rv.IsValid() {
+		cp := reflect.New(rv.Type()).Elem()
+		cp.Set(deepCopy(rv))
+		unmaskSecrets(cp)
+		v = cp.Interface()
+	}
+	return encodeJSON(v)
+}
+
+func encodeJSON(v interface{}) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// RegisterEncoder registers Encoder for file extension (e.g. ".toml", ".yaml").
+// JSON encoder is registered by default. If enc is nil, the encoder for ext is removed.
+func RegisterEncoder(ext string, enc Encoder) {
+	ext = normalizeExt(ext)
+	encoderMutex.Lock()
+	defer encoderMutex.Unlock()
+	if enc == nil {
+		delete(encoderMap, ext)
+		return
+	}
+	encoderMap[ext] = enc
+}
+
+// EncoderFor returns Encoder registered for extension of path
+func EncoderFor(path string) (Encoder, bool) {
+	encoderMutex.RLock()
+	defer encoderMutex.RUnlock()
+	enc, ok := encoderMap[normalizeExt(filepath.Ext(path))]
+	return enc, ok
+}
+
+// Save encodes v and writes it to Path(appName, fileName) atomically (see SaveFile).
+func Save(appName, fileName string, v interface{}) error {
+	path := Path(appName, fileName)
+	if len(path) == 0 {
+		return fmt.Errorf("%w: app %q, file %q", ErrInvalidPath, appName, fileName)
+	}
+	return SaveFile(path, v)
+}
+
+// SaveFile encodes v and writes it to path atomically.
+// The directory is created with permission 0700 if not exists.
+// Data is written to temporary file in the same directory and renamed to path after fsync,
+// so path always has either the previous or the new content.
+// The previous file is kept with BackupSuffix, and its file mode is preserved (0600 for new file).
+func SaveFile(path string, v interface{}) error {
+	enc, ok := EncoderFor(path)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNoEncoder, path)
+	}
+	data, err := enc.Encode(v)
+	if err != nil {
+		return fmt.Errorf("cannot encode %s: %w", path, err)
+	}
+	return writeFile(path, data)
+}
+
+// writeFile writes data to path atomically with backup of previous file
+func writeFile(path string, data []byte) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved // replace the target of symbolic link, not the link itself
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return wrapPathError(err)
+	}
+	mode := fs.FileMode(0600)
+	info, err := os.Stat(path)
+	exists := err == nil
+	if exists {
+		mode = info.Mode().Perm()
+	}
+
+	tmpName, err := writeTemp(dir, filepath.Base(path), data, mode)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpName) //nolint:errcheck // no-op after rename
+
+	if exists {
+		if err := backup(path); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return wrapPathError(err)
+	}
+	syncDir(dir)
+	return nil
+}
+
+// createFile writes data to new file at path atomically. It fails with fs.ErrExist if path exists
+// (including dangling symbolic link), even if the file is created by other concurrently.
+func createFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return wrapPathError(err)
+	}
+	tmpName, err := writeTemp(dir, filepath.Base(path), data, 0600)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpName) //nolint:errcheck
+	err = os.Link(tmpName, path)
+	if err == nil {
+		syncDir(dir)
+		return nil
+	}
+	if errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	// hard link is not supported by file system
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600) //nolint:gosec
+	if err != nil {
+		return wrapPathError(err)
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+		return err
+	}
+	return file.Close()
+}
+
+// writeTemp writes data to temporary file for base in dir with mode, and returns name of the file
+func writeTemp(dir, base string, data []byte, mode fs.FileMode) (string, error) {
+	tmp, err := os.CreateTemp(dir, "."+base+".tmp-*")
+	if err != nil {
+		return "", wrapPathError(err)
+	}
+	err = func() error {
+		if _, err := tmp.Write(data); err != nil {
+			return err
+		}
+		if err := tmp.Chmod(mode); err != nil {
+			return err
+		}
+		return tmp.Sync()
+	}()
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// backup keeps current file at path as path+BackupSuffix
+func backup(path string) error {
+	bak := path + BackupSuffix
+	if err := os.Remove(bak); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Link(path, bak); err == nil {
+		return nil
+	}
+	src, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return wrapPathError(err)
+	}
+	defer src.Close() //nolint:errcheck
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(bak, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm()) //nolint:gosec
+	if err != nil {
+		return wrapPathError(err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// syncDir flushes directory entry after rename (best effort)
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil { //nolint:gosec
+		_ = d.Sync()
+		_ = d.Close()
+	}
+}
+
+func wrapPathError(err error) error {
+	if errors.Is(err, fs.ErrPermission) {
+		return fmt.Errorf("%w: %w", ErrPermission, err)
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/goark/gocli/config"
+)
+
+func TestSaveFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "app")
+	path := filepath.Join(dir, "config.json")
+
+	if err := config.SaveFile(path, map[string]string{"name": "foo"}); err != nil {
+		t.Fatalf("config.SaveFile() is \"%v\", want nil error.", err)
+	}
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat(dir); err != nil || info.Mode().Perm() != 0700 {
+			t.Errorf("mode of directory is %v (%v), want %v.", info.Mode().Perm(), err, os.FileMode(0700))
+		}
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("mode of file is %v (%v), want %v.", info.Mode().Perm(), err, os.FileMode(0600))
+		}
+		if err := os.Chmod(path, 0640); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := config.SaveFile(path, map[string]string{"name": "bar"}); err != nil {
+		t.Fatalf("config.SaveFile() is \"%v\", want nil error.", err)
+	}
+	var cfg map[string]string
+	if err := config.LoadFile(path, &cfg); err != nil || cfg["name"] != "bar" {
+		t.Errorf("config.LoadFile() = %v (%v), want name \"bar\".", cfg, err)
+	}
+	if data, err := os.ReadFile(path + config.BackupSuffix); err != nil || !strings.Contains(string(data), `"foo"`) {
+		t.Errorf("backup file is \"%s\" (%v), want name \"foo\".", data, err)
+	}
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0640 {
+			t.Errorf("mode of file is %v (%v), want %v.", info.Mode().Perm(), err, os.FileMode(0640))
+		}
+	}
+	if files, err := filepath.Glob(filepath.Join(dir, "*.tmp-*")); err != nil || len(files) != 0 {
+		t.Errorf("temporary files = %v, want none.", files)
+	}
+}
+
+func TestSaveFileError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.ini")
+	if err := config.SaveFile(path, map[string]string{}); !errors.Is(err, config.ErrNoEncoder) {
+		t.Errorf("config.SaveFile() is \"%v\", want \"%v\".", err, config.ErrNoEncoder)
+	}
+	path = filepath.Join(t.TempDir(), "config.json")
+	if err := config.SaveFile(path, func() {}); err == nil {
+		t.Error("config.SaveFile(func) is nil, want error.")
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("os.Stat() is \"%v\", want \"%v\".", err, os.ErrNotExist)
+	}
+}
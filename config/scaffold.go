@@ -0,0 +1,433 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unsafe"
+)
+
+// ErrExist is error for existing configuration file in Init
+var ErrExist = errors.New("configuration file already exists")
+
+type initOptions struct {
+	force bool
+}
+
+// InitOptFunc is self-referential function for functional options pattern (Init function)
+type InitOptFunc func(*initOptions)
+
+// WithForce returns function for overwriting existing file (backup is kept as Save)
+func WithForce(force bool) InitOptFunc {
+	return func(o *initOptions) {
+		o.force = force
+	}
+}
+
+// Init writes default configuration file generated from template (see Scaffold) to Path(appName, fileName),
+// and returns the path written. It refuses to overwrite existing file unless WithForce(true) is given.
+func Init(appName, fileName string, template interface{}, opts ...InitOptFunc) (string, error) {
+	path := Path(appName, fileName)
+	if len(path) == 0 {
+		return "", fmt.Errorf("%w: app %q, file %q", ErrInvalidPath, appName, fileName)
+	}
+	if err := InitFile(path, template, opts...); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// InitFile writes default configuration file generated from template to path.
+func InitFile(path string, template interface{}, opts ...InitOptFunc) error {
+	o := &initOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	existErr := fmt.Errorf("%w: %w", ErrExist, &fs.PathError{Op: "init", Path: path, Err: fs.ErrExist})
+	if !o.force {
+		if _, err := os.Lstat(path); err == nil {
+			return existErr // fail fast before generating content
+		}
+	}
+	data, err := Scaffold(path, template)
+	if err != nil {
+		return err
+	}
+	if o.force {
+		return writeFile(path, data)
+	}
+	if err := createFile(path, data); !errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	return existErr // created by other since the check above
+}
+
+// Scaffold returns content of default configuration file for format of path, generated from template struct
+// with `default` tags applied. For TOML (".toml") and YAML (".yaml", ".yml") files, `doc:"..."` tags of fields
+// become comments and `toml`/`yaml` tags (or `json` tags) give key names.
+// Other formats are encoded by Encoder registered for the extension.
+func Scaffold(path string, template interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(template)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot generate configuration file from %T: not a struct", template)
+	}
+	cp := reflect.New(rv.Type())
+	cp.Elem().Set(deepCopy(rv))
+	if err := SetDefaults(cp.Interface()); err != nil {
+		return nil, err
+	}
+
+	switch normalizeExt(filepath.Ext(path)) {
+	case ".toml":
+		buf := &bytes.Buffer{}
+		if err := writeTOMLTable(buf, cp.Elem(), nil); err != nil {
+			return nil, err
+		}
+		return bytes.TrimLeft(buf.Bytes(), "\n"), nil
+	case ".yaml", ".yml":
+		lines, err := yamlLines(cp.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strings.Join(lines, "\n") + "\n"), nil
+	}
+	enc, ok := EncoderFor(path)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoEncoder, path)
+	}
+	return enc.Encode(cp.Interface())
+}
+
+// deepCopy returns copy of v not sharing pointers, slices and maps
+// (unexported fields except embedded ones are copied shallowly)
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.New(v.Type().Elem())
+		n.Elem().Set(deepCopy(v.Elem()))
+		return n
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.New(v.Type()).Elem()
+		n.Set(deepCopy(v.Elem()))
+		return n
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			n.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return n
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			n.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return n
+	case reflect.Struct:
+		n := reflect.New(v.Type()).Elem()
+		n.Set(v)
+		for i := 0; i < n.NumField(); i++ {
+			if f, ok := settableField(n, i); ok {
+				f.Set(deepCopy(f))
+			}
+		}
+		return n
+	}
+	return v
+}
+
+// settableField returns i-th field of addressable struct v if it is settable.
+// Embedded struct of unexported type is settable too, because encoding/json encodes its exported fields.
+func settableField(v reflect.Value, i int) (reflect.Value, bool) {
+	f := v.Field(i)
+	if f.CanSet() {
+		return f, true
+	}
+	if v.Type().Field(i).Anonymous && v.CanAddr() {
+		return reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem(), true //nolint:gosec
+	}
+	return f, false
+}
+
+type scaffoldField struct {
+	key   string
+	doc   string
+	value reflect.Value
+}
+
+// scaffoldFields returns fields of struct or entries of map in rv
+func scaffoldFields(rv reflect.Value, tagName string) []scaffoldField {
+	fields := []scaffoldField{}
+	if rv.Kind() == reflect.Map {
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, k := range keys {
+			if v, ok := scaffoldValue(rv.MapIndex(k)); ok {
+				fields = append(fields, scaffoldField{key: fmt.Sprint(k), value: v})
+			}
+		}
+		return fields
+	}
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, hasTag := f.Tag.Lookup(tagName)
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && !hasTag && f.Tag.Get("json") == "" && isTable(rv.Field(i)) {
+			if v, ok := scaffoldValue(rv.Field(i)); ok {
+				fields = append(fields, scaffoldFields(v, tagName)...)
+			}
+			continue
+		}
+		if len(name) == 0 {
+			var ok bool
+			if name, ok = fieldKey(f); !ok {
+				continue
+			}
+		}
+		if v, ok := scaffoldValue(rv.Field(i)); ok {
+			fields = append(fields, scaffoldField{key: name, doc: f.Tag.Get("doc"), value: v})
+		}
+	}
+	return fields
+}
+
+// scaffoldValue dereferences v. Nil pointer to struct is expanded to default value.
+func scaffoldValue(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if !v.IsNil() {
+			v = v.Elem()
+			continue
+		}
+		if v.Kind() == reflect.Pointer && isNested(v.Type()) {
+			nv := reflect.New(v.Type().Elem())
+			if err := SetDefaults(nv.Interface()); err == nil {
+				return nv.Elem(), true
+			}
+		}
+		return reflect.Value{}, false
+	}
+	return v, true
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+func isTable(v reflect.Value) bool {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return isNested(v.Type())
+		}
+		v = v.Elem()
+	}
+	if v.Type().Implements(textMarshalerType) || reflect.PointerTo(v.Type()).Implements(textMarshalerType) {
+		return false
+	}
+	return (v.Kind() == reflect.Struct) || (v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String)
+}
+
+func isTableArray(v reflect.Value) bool {
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Len() == 0 {
+		return false
+	}
+	return isTable(v.Index(0))
+}
+
+// scalar returns v in literal form which is valid in TOML and YAML (flow style)
+func scalar(v reflect.Value) (string, error) {
+	if v.Type() == durationType {
+		return scalarJSON(fmt.Sprint(v.Interface()))
+	}
+	if m, ok := textMarshaler(v); ok {
+		text, err := m.MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return scalarJSON(string(text))
+	}
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8 {
+		elems := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			ev, ok := scaffoldValue(v.Index(i))
+			if !ok {
+				continue
+			}
+			s, err := scalar(ev)
+			if err != nil {
+				return "", err
+			}
+			elems = append(elems, s)
+		}
+		return "[" + strings.Join(elems, ", ") + "]", nil
+	}
+	return scalarJSON(v.Interface())
+}
+
+func scalarJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+func textMarshaler(v reflect.Value) (encoding.TextMarshaler, bool) {
+	if v.Type().Implements(textMarshalerType) {
+		return v.Interface().(encoding.TextMarshaler), true
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textMarshalerType) {
+		return v.Addr().Interface().(encoding.TextMarshaler), true
+	}
+	return nil, false
+}
+
+func docLines(doc string) []string {
+	if len(doc) == 0 {
+		return nil
+	}
+	lines := strings.Split(doc, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("# "+line, " ")
+	}
+	return lines
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func quoteKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+	s, _ := scalarJSON(key)
+	return s
+}
+
+func writeTOMLTable(buf *bytes.Buffer, rv reflect.Value, path []string) error {
+	tables := []scaffoldField{}
+	for _, f := range scaffoldFields(rv, "toml") {
+		if isTable(f.value) || isTableArray(f.value) {
+			tables = append(tables, f)
+			continue
+		}
+		s, err := scalar(f.value)
+		if err != nil {
+			return err
+		}
+		for _, line := range docLines(f.doc) {
+			buf.WriteString(line + "\n")
+		}
+		fmt.Fprintf(buf, "%s = %s\n", quoteKey(f.key), s)
+	}
+	for _, f := range tables {
+		p := append(path[:len(path):len(path)], quoteKey(f.key))
+		buf.WriteString("\n")
+		for _, line := range docLines(f.doc) {
+			buf.WriteString(line + "\n")
+		}
+		if isTable(f.value) {
+			fmt.Fprintf(buf, "[%s]\n", strings.Join(p, "."))
+			if err := writeTOMLTable(buf, f.value, p); err != nil {
+				return err
+			}
+			continue
+		}
+		for i := 0; i < f.value.Len(); i++ {
+			ev, ok := scaffoldValue(f.value.Index(i))
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(buf, "[[%s]]\n", strings.Join(p, "."))
+			if err := writeTOMLTable(buf, ev, p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func yamlLines(rv reflect.Value) ([]string, error) {
+	lines := []string{}
+	for _, f := range scaffoldFields(rv, "yaml") {
+		lines = append(lines, docLines(f.doc)...)
+		key := quoteKey(f.key)
+		switch {
+		case isTable(f.value):
+			sub, err := yamlLines(f.value)
+			if err != nil {
+				return nil, err
+			}
+			if len(sub) == 0 {
+				lines = append(lines, key+": {}")
+				continue
+			}
+			lines = append(lines, key+":")
+			for _, line := range sub {
+				lines = append(lines, "  "+line)
+			}
+		case isTableArray(f.value):
+			lines = append(lines, key+":")
+			for i := 0; i < f.value.Len(); i++ {
+				ev, ok := scaffoldValue(f.value.Index(i))
+				if !ok {
+					continue
+				}
+				sub, err := yamlLines(ev)
+				if err != nil {
+					return nil, err
+				}
+				lines = append(lines, yamlItem(sub)...)
+			}
+		default:
+			s, err := scalar(f.value)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, key+": "+s)
+		}
+	}
+	return lines, nil
+}
+
+// yamlItem returns lines of mapping as item of block sequence
+func yamlItem(sub []string) []string {
+	lines := make([]string, 0, len(sub)+1)
+	dash := false
+	for _, line := range sub {
+		switch {
+		case dash:
+			lines = append(lines, "    "+line)
+		case strings.HasPrefix(line, "#"): // comments before the first key
+			lines = append(lines, "  "+line)
+		default:
+			lines = append(lines, "  - "+line)
+			dash = true
+		}
+	}
+	if !dash {
+		lines = append(lines, "  - {}")
+	}
+	return lines
+}
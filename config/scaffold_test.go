@@ -0,0 +1,159 @@
+package config_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goark/gocli/config"
+)
+
+type scaffoldServer struct {
+	Host string `json:"host" doc:"Host name"`
+	Port int    `json:"port" default:"443"`
+}
+
+type scaffoldConfig struct {
+	Name    string            `json:"name" toml:"app_name" doc:"Application name"`
+	Timeout time.Duration     `json:"timeout" default:"30s" doc:"Timeout of requests\n(e.g. 30s, 1m)"`
+	Tags    []string          `json:"tags" default:"a,b"`
+	Secret  string            `json:"-"`
+	DB      scaffoldServer    `json:"db" doc:"Database"`
+	Mirrors []scaffoldServer  `json:"mirrors"`
+	Proxy   *scaffoldServer   `json:"proxy"`
+	Labels  map[string]string `json:"labels"`
+}
+
+var scaffoldTemplate = scaffoldConfig{
+	Name:    "foo",
+	Mirrors: []scaffoldServer{{Host: "a.example.com"}},
+	Labels:  map[string]string{"env": "dev", "my label": "x"},
+}
+
+func TestScaffold(t *testing.T) {
+	testCases := []struct {
+		fileName string
+		content  string
+	}{
+		{fileName: "config.toml", content: `# Application name
+app_name = "foo"
+# Timeout of requests
+# (e.g. 30s, 1m)
+timeout = "30s"
+tags = ["a", "b"]
+
+# Database
+[db]
+# Host name
+host = ""
+port = 443
+
+[[mirrors]]
+# Host name
+host = "a.example.com"
+port = 443
+
+[proxy]
+# Host name
+host = ""
+port = 443
+
+[labels]
+env = "dev"
+"my label" = "x"
+`},
+		{fileName: "config.yaml", content: `# Application name
+name: "foo"
+# Timeout of requests
+# (e.g. 30s, 1m)
+timeout: "30s"
+tags: ["a", "b"]
+# Database
+db:
+  # Host name
+  host: ""
+  port: 443
+mirrors:
+  # Host name
+  - host: "a.example.com"
+    port: 443
+proxy:
+  # Host name
+  host: ""
+  port: 443
+labels:
+  env: "dev"
+  "my label": "x"
+`},
+	}
+	for _, tc := range testCases {
+		data, err := config.Scaffold(tc.fileName, &scaffoldTemplate)
+		if err != nil {
+			t.Errorf("config.Scaffold(\"%v\") is \"%v\", want nil error.", tc.fileName, err)
+			continue
+		}
+		if string(data) != tc.content {
+			t.Errorf("config.Scaffold(\"%v\") = \n%s\nwant\n%s", tc.fileName, data, tc.content)
+		}
+	}
+	if scaffoldTemplate.Timeout != 0 || scaffoldTemplate.Mirrors[0].Port != 0 {
+		t.Error("config.Scaffold() modifies template.")
+	}
+}
+
+func TestInitFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app", "config.json")
+	if err := config.InitFile(path, scaffoldTemplate); err != nil {
+		t.Fatalf("config.InitFile() is \"%v\", want nil error.", err)
+	}
+	var cfg scaffoldConfig
+	if err := config.LoadFile(path, &cfg); err != nil || cfg.Name != "foo" || cfg.DB.Port != 443 {
+		t.Errorf("config.LoadFile() = %+v (%v).", cfg, err)
+	}
+
+	err := config.InitFile(path, scaffoldTemplate)
+	if !errors.Is(err, config.ErrExist) || !errors.Is(err, os.ErrExist) {
+		t.Errorf("config.InitFile() is \"%v\", want \"%v\".", err, config.ErrExist)
+	}
+	if err := config.InitFile(path, scaffoldConfig{Name: "bar"}, config.WithForce(true)); err != nil {
+		t.Errorf("config.InitFile() with force is \"%v\", want nil error.", err)
+	}
+	if _, err := os.Stat(path + config.BackupSuffix); err != nil {
+		t.Errorf("backup file is not found: %v", err)
+	}
+	if err := config.InitFile(path, "string"); err == nil {
+		t.Error("config.InitFile(string) is nil, want error.")
+	}
+}
+
+func TestInitFileConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = config.InitFile(path, scaffoldConfig{Name: fmt.Sprintf("app%d", i)})
+		}(i)
+	}
+	wg.Wait()
+
+	created := ""
+	for i, err := range errs {
+		switch {
+		case err == nil && created == "":
+			created = fmt.Sprintf("app%d", i)
+		case !errors.Is(err, config.ErrExist):
+			t.Errorf("config.InitFile() is \"%v\", want \"%v\" except one.", err, config.ErrExist)
+		}
+	}
+	var cfg scaffoldConfig
+	if err := config.LoadFile(path, &cfg); err != nil || cfg.Name != created {
+		t.Errorf("config.LoadFile() = %+v (%v), want name \"%s\" of the only successful call.", cfg, err, created)
+	}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+//Scope is scope of configuration file
+type Scope int
+
+//Scopes of configuration file (in precedence order, lowest first)
+const (
+	ScopeSystem Scope = iota
+	ScopeUser
+	ScopeProject
+)
+
+var scopeMap = map[Scope]string{
+	ScopeSystem:  "system",
+	ScopeUser:    "user",
+	ScopeProject: "project",
+}
+
+//String is Stringer method
+func (s Scope) String() string {
+	if str, ok := scopeMap[s]; ok {
+		return str
+	}
+	return "unknown"
+}
+
+//Location is candidate location of configuration file
+type Location struct {
+	Path   string
+	Scope  Scope
+	Exists bool
+}
+
+//SearchPaths returns candidate paths of configuration file in precedence order (lowest first).
+//
+//	system:  /etc/<appName>/<fileName>, $XDG_CONFIG_DIRS/<appName>/<fileName> (default /etc/xdg; %ProgramData% on Windows)
+//	user:    Path(appName, fileName)
+//	project: ./.<appName>/<fileName>
+//
+//A file later in the list overrides earlier ones.
+func SearchPaths(appName, fileName string) []string {
+	locs := candidates(appName, fileName)
+	paths := make([]string, 0, len(locs))
+	for _, loc := range locs {
+		paths = append(paths, loc.Path)
+	}
+	return paths
+}
+
+//Find returns all candidate locations of configuration file in precedence order (lowest first), and reports which ones exist.
+func Find(appName, fileName string) []Location {
+	locs := candidates(appName, fileName)
+	for i := range locs {
+		if info, err := os.Stat(locs[i].Path); err == nil && !info.IsDir() {
+			locs[i].Exists = true
+		}
+	}
+	return locs
+}
+
+func candidates(appName, fileName string) []Location {
+	if len(fileName) == 0 || includeSlash(fileName) || includeSlash(appName) {
+		return nil
+	}
+	locs := []Location{}
+	for _, dir := range systemDirs() {
+		locs = append(locs, Location{Path: filepath.Join(dir, appName, fileName), Scope: ScopeSystem})
+	}
+	if path := Path(appName, fileName); len(path) > 0 {
+		locs = append(locs, Location{Path: path, Scope: ScopeUser})
+	}
+	if len(appName) > 0 {
+		if wd, err := os.Getwd(); err == nil {
+			locs = append(locs, Location{Path: filepath.Join(wd, "."+appName, fileName), Scope: ScopeProject})
+		}
+	}
+	return uniqueLocations(locs)
+}
+
+//systemDirs returns system-wide configuration directories (lowest precedence first)
+func systemDirs() []string {
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("ProgramData"); len(dir) > 0 {
+			return []string{dir}
+		}
+		return nil
+	}
+	dirs := []string{"/etc"}
+	xdgDirs := filepath.SplitList(os.Getenv("XDG_CONFIG_DIRS"))
+	if len(xdgDirs) == 0 {
+		xdgDirs = []string{"/etc/xdg"}
+	}
+	for i := len(xdgDirs) - 1; i >= 0; i-- { // first entry of $XDG_CONFIG_DIRS is most important
+		if dir := strings.TrimSpace(xdgDirs[i]); filepath.IsAbs(dir) {
+			dirs = append(dirs, filepath.Clean(dir))
+		}
+	}
+	return dirs
+}
+
+func uniqueLocations(locs []Location) []Location {
+	seen := map[string]bool{}
+	unq := make([]Location, 0, len(locs))
+	for _, loc := range locs {
+		if !seen[loc.Path] {
+			seen[loc.Path] = true
+			unq = append(unq, loc)
+		}
+	}
+	return unq
+}
@@ -0,0 +1,88 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/goark/gocli/config"
+)
+
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+func TestFind(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("system directories differ on Windows")
+	}
+	sys1 := t.TempDir()
+	sys2 := t.TempDir()
+	project := t.TempDir()
+	t.Setenv("XDG_CONFIG_DIRS", sys1+string(filepath.ListSeparator)+sys2)
+	chdir(t, project)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(sys2, "gocli-test", "config.json"), "{}")
+	writeFile(t, filepath.Join(wd, ".gocli-test", "config.json"), "{}")
+
+	want := []config.Location{
+		{Path: filepath.Join("/etc", "gocli-test", "config.json"), Scope: config.ScopeSystem},
+		{Path: filepath.Join(sys2, "gocli-test", "config.json"), Scope: config.ScopeSystem, Exists: true},
+		{Path: filepath.Join(sys1, "gocli-test", "config.json"), Scope: config.ScopeSystem},
+		{Path: config.Path("gocli-test", "config.json"), Scope: config.ScopeUser},
+		{Path: filepath.Join(wd, ".gocli-test", "config.json"), Scope: config.ScopeProject, Exists: true},
+	}
+	if locs := config.Find("gocli-test", "config.json"); !reflect.DeepEqual(locs, want) {
+		t.Errorf("config.Find() = %+v, want %+v.", locs, want)
+	}
+	paths := config.SearchPaths("gocli-test", "config.json")
+	if len(paths) != len(want) || paths[4] != want[4].Path {
+		t.Errorf("config.SearchPaths() = %v, want %v.", paths, want)
+	}
+}
+
+func TestSearchPathsInvalid(t *testing.T) {
+	testCases := []struct {
+		appName  string
+		fileName string
+	}{
+		{appName: "foo", fileName: ""},
+		{appName: "../foo", fileName: "bar"},
+		{appName: "foo", fileName: "bar/bar"},
+	}
+	for _, tc := range testCases {
+		if paths := config.SearchPaths(tc.appName, tc.fileName); len(paths) != 0 {
+			t.Errorf("config.SearchPaths(\"%v\", \"%v\") = %v, want empty.", tc.appName, tc.fileName, paths)
+		}
+	}
+}
+
+func TestScopeString(t *testing.T) {
+	testCases := []struct {
+		scope config.Scope
+		str   string
+	}{
+		{scope: config.ScopeSystem, str: "system"},
+		{scope: config.ScopeUser, str: "user"},
+		{scope: config.ScopeProject, str: "project"},
+		{scope: config.Scope(9), str: "unknown"},
+	}
+	for _, tc := range testCases {
+		if str := tc.scope.String(); str != tc.str {
+			t.Errorf("Scope.String() = \"%v\", want \"%v\".", str, tc.str)
+		}
+	}
+}
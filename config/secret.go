@@ -0,0 +1,143 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// ErrSecretNotFound is error for unresolvable reference of Secret
+var ErrSecretNotFound = errors.New("secret not found")
+
+const redactedText = "******"
+
+// unmaskedPrefix marks Secret encoded as is by MarshalJSON (used only in copy of configuration encoded by Save)
+const unmaskedPrefix = "\x00unmasked:"
+
+// Prefixes of references in Secret
+const (
+	SecretFilePrefix = "file:"
+	SecretEnvPrefix  = "env:"
+)
+
+// Secret is string type for sensitive value (API token, password, ...) in configuration.
+// The value is redacted by String, Format and MarshalJSON methods (and so Dump function), so printing, logging
+// or dumping configuration does not leak it. Save function writes the value as is, so saved configuration keeps the secret.
+// Encoders registered by RegisterEncoder write the value as is, unless they use MarshalJSON method.
+// The value may be a reference to file ("file:/run/secrets/token") or environment variable ("env:TOKEN");
+// references are printed as is because they are not secret. Use Reveal method to get the actual value.
+type Secret string
+
+var secretType = reflect.TypeOf(Secret(""))
+
+// IsRef reports whether s is reference to file or environment variable
+func (s Secret) IsRef() bool {
+	return strings.HasPrefix(string(s), SecretFilePrefix) || strings.HasPrefix(string(s), SecretEnvPrefix)
+}
+
+// Reveal returns the actual value of secret, resolving reference to file (trailing newline is trimmed) or environment variable.
+func (s Secret) Reveal() (string, error) {
+	str := string(s)
+	switch {
+	case strings.HasPrefix(str, SecretFilePrefix):
+		data, err := os.ReadFile(strings.TrimPrefix(str, SecretFilePrefix)) //nolint:gosec
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrSecretNotFound, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case strings.HasPrefix(str, SecretEnvPrefix):
+		name := strings.TrimPrefix(str, SecretEnvPrefix)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("%w: environment variable %s is not set", ErrSecretNotFound, name)
+		}
+		return value, nil
+	}
+	return str, nil
+}
+
+// String is Stringer method (redacted)
+func (s Secret) String() string {
+	if len(s) == 0 || s.IsRef() {
+		return string(s)
+	}
+	return redactedText
+}
+
+// GoString is GoStringer method (redacted)
+func (s Secret) GoString() string {
+	return fmt.Sprintf("config.Secret(%q)", s.String())
+}
+
+// Format is fmt.Formatter method (redacted for every verb)
+func (s Secret) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('#'):
+		_, _ = io.WriteString(f, s.GoString())
+	case verb == 'q':
+		_, _ = fmt.Fprintf(f, "%q", s.String())
+	default:
+		_, _ = io.WriteString(f, s.String())
+	}
+}
+
+// MarshalJSON is json.Marshaler method (redacted)
+func (s Secret) MarshalJSON() ([]byte, error) {
+	if str, ok := strings.CutPrefix(string(s), unmaskedPrefix); ok {
+		return json.Marshal(str)
+	}
+	return json.Marshal(s.String())
+}
+
+// Dump writes effective configuration v to w in indented JSON, with values of Secret redacted.
+func Dump(w io.Writer, v interface{}) error {
+	data, err := encodeJSON(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// unmaskSecrets marks values of Secret in v (copy of configuration) to be encoded as is by MarshalJSON
+func unmaskSecrets(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			unmaskSecrets(v.Elem())
+		}
+	case reflect.Interface:
+		if !v.IsNil() && v.CanSet() {
+			e := reflect.New(v.Elem().Type()).Elem()
+			e.Set(v.Elem())
+			unmaskSecrets(e)
+			v.Set(e)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			unmaskSecrets(v.Index(i))
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			e := reflect.New(v.Type().Elem()).Elem()
+			e.Set(iter.Value())
+			unmaskSecrets(e)
+			v.SetMapIndex(iter.Key(), e)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if f, ok := settableField(v, i); ok {
+				unmaskSecrets(f)
+			}
+		}
+	case reflect.String:
+		if v.Type() == secretType && v.CanSet() && v.Len() > 0 {
+			v.SetString(unmaskedPrefix + v.String())
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package config_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goark/gocli/config"
+)
+
+func TestSecretFormat(t *testing.T) {
+	s := config.Secret("token-1234")
+	testCases := []struct {
+		format string
+		str    string
+	}{
+		{format: "%v", str: "******"},
+		{format: "%s", str: "******"},
+		{format: "%q", str: `"******"`},
+		{format: "%x", str: "******"},
+		{format: "%#v", str: `config.Secret("******")`},
+		{format: "%+v", str: "{Token:******}"},
+	}
+	for _, tc := range testCases {
+		var str string
+		if tc.format == "%+v" {
+			str = fmt.Sprintf(tc.format, struct{ Token config.Secret }{Token: s})
+		} else {
+			str = fmt.Sprintf(tc.format, s)
+		}
+		if str != tc.str {
+			t.Errorf("fmt.Sprintf(\"%v\", Secret) = %v, want %v.", tc.format, str, tc.str)
+		}
+	}
+	if str := config.Secret("env:TOKEN").String(); str != "env:TOKEN" {
+		t.Errorf("Secret.String() = %v, want %v.", str, "env:TOKEN")
+	}
+}
+
+func TestSecretReveal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	writeFile(t, path, "file-token\n")
+	t.Setenv("GOCLI_TEST_TOKEN", "env-token")
+
+	testCases := []struct {
+		secret config.Secret
+		value  string
+		err    error
+	}{
+		{secret: "literal", value: "literal"},
+		{secret: config.Secret("file:" + path), value: "file-token"},
+		{secret: "env:GOCLI_TEST_TOKEN", value: "env-token"},
+		{secret: "env:GOCLI_TEST_NONE", err: config.ErrSecretNotFound},
+		{secret: config.Secret("file:" + path + ".none"), err: config.ErrSecretNotFound},
+	}
+	for _, tc := range testCases {
+		value, err := tc.secret.Reveal()
+		if !errors.Is(err, tc.err) || value != tc.value {
+			t.Errorf("Secret.Reveal() = %v, %v, want %v, %v.", value, err, tc.value, tc.err)
+		}
+	}
+}
+
+type secretInner struct {
+	Key config.Secret `json:"key"`
+}
+
+func TestDump(t *testing.T) {
+	cfg := struct {
+		User     string        `json:"user"`
+		Password config.Secret `json:"password"`
+		Token    config.Secret `json:"token"`
+	}{User: "alice", Password: "p@ssw0rd", Token: "env:TOKEN"}
+
+	buf := &bytes.Buffer{}
+	if err := config.Dump(buf, cfg); err != nil {
+		t.Fatalf("config.Dump() is \"%v\", want nil error.", err)
+	}
+	if str := buf.String(); strings.Contains(str, "p@ssw0rd") || !strings.Contains(str, `"password": "******"`) || !strings.Contains(str, `"token": "env:TOKEN"`) {
+		t.Errorf("config.Dump() = %v.", str)
+	}
+
+	nested := struct {
+		Accounts map[string]config.Secret `json:"accounts"`
+		Keys     []config.Secret          `json:"keys"`
+		Proxy    *struct {
+			Password config.Secret `json:"password"`
+		} `json:"proxy"`
+	}{
+		Accounts: map[string]config.Secret{"alice": "p@ssw0rd"},
+		Keys:     []config.Secret{"key-1234"},
+		Proxy: &struct {
+			Password config.Secret `json:"password"`
+		}{Password: "proxy-pass"},
+	}
+	buf.Reset()
+	if err := config.Dump(buf, nested); err != nil {
+		t.Fatalf("config.Dump() is \"%v\", want nil error.", err)
+	}
+	for _, secret := range []string{"p@ssw0rd", "key-1234", "proxy-pass"} {
+		if strings.Contains(buf.String(), secret) {
+			t.Errorf("config.Dump() = %v, leaks %v.", buf.String(), secret)
+		}
+	}
+	if nested.Accounts["alice"] != "p@ssw0rd" || nested.Keys[0] != "key-1234" || nested.Proxy.Password != "proxy-pass" {
+		t.Errorf("config.Dump() modifies configuration: %+v.", nested)
+	}
+
+	embedded := struct {
+		secretInner
+		Token config.Secret `json:"token"`
+	}{secretInner: secretInner{Key: "sekrit"}, Token: "abc"}
+	buf.Reset()
+	if err := config.Dump(buf, embedded); err != nil {
+		t.Fatalf("config.Dump() is \"%v\", want nil error.", err)
+	}
+	data, err := json.Marshal(embedded)
+	if err != nil {
+		t.Fatalf("json.Marshal() is \"%v\", want nil error.", err)
+	}
+	for _, str := range []string{buf.String(), string(data)} {
+		if strings.Contains(str, "sekrit") || strings.Contains(str, "abc") {
+			t.Errorf("encoded configuration = %v, leaks secret.", str)
+		}
+	}
+}
+
+func TestSecretSaveRoundTrip(t *testing.T) {
+	type secretConfig struct {
+		secretInner
+		User  string          `json:"user"`
+		Token config.Secret   `json:"token"`
+		Keys  []config.Secret `json:"keys"`
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"user":"alice","token":"real-token","key":"inner-key","keys":["key-1"]}`)
+
+	cfg := secretConfig{}
+	if err := config.LoadFile(path, &cfg); err != nil {
+		t.Fatalf("config.LoadFile() is \"%v\", want nil error.", err)
+	}
+	cfg.User = "bob"
+	if err := config.SaveFile(path, cfg); err != nil {
+		t.Fatalf("config.SaveFile() is \"%v\", want nil error.", err)
+	}
+	saved := secretConfig{}
+	if err := config.LoadFile(path, &saved); err != nil {
+		t.Fatalf("config.LoadFile() is \"%v\", want nil error.", err)
+	}
+	if saved.User != "bob" || saved.Token != "real-token" || saved.Key != "inner-key" || len(saved.Keys) != 1 || saved.Keys[0] != "key-1" {
+		t.Errorf("saved configuration = %#v, want secrets kept.", saved)
+	}
+	if cfg.Token != "real-token" || cfg.Key != "inner-key" || cfg.Keys[0] != "key-1" {
+		t.Errorf("config.SaveFile() modifies configuration: %#v.", cfg)
+	}
+}
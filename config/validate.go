@@ -0,0 +1,369 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// FieldError is validation error of a field in configuration
+type FieldError struct {
+	Path    string // key path of field (e.g. "db.port", "servers[0].host")
+	Rule    string
+	Message string
+}
+
+// Error method for error interface
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidationError is error type listing every failing field
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+// Error method for error interface
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+	return "invalid configuration: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns errors of fields
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		errs = append(errs, fe)
+	}
+	return errs
+}
+
+// SetDefaults sets value of `default:"..."` tag to every field which has zero value in struct pointed by v.
+// The tag value is converted in the same way as environment variables (see BindEnv).
+// SetDefaults does nothing if v is not a pointer to struct.
+func SetDefaults(v interface{}) error {
+	rv, ok := structValue(v)
+	if !ok {
+		return nil
+	}
+	return setDefaults(rv, "")
+}
+
+func setDefaults(rv reflect.Value, path string) error {
+	errs := []error{}
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, ok := fieldKey(f)
+		if !ok {
+			continue
+		}
+		fpath := joinKey(path, key)
+		fv := rv.Field(i)
+		if def, ok := f.Tag.Lookup("default"); ok && fv.IsZero() {
+			if err := setString(fv, def); err != nil {
+				errs = append(errs, fmt.Errorf("invalid default value %q of %s: %w", def, fpath, err))
+			}
+			continue
+		}
+		switch {
+		case isNested(f.Type):
+			if err := setDefaultsNested(fv, fpath); err != nil {
+				errs = append(errs, err)
+			}
+		case f.Type.Kind() == reflect.Slice && isNested(f.Type.Elem()):
+			for j := 0; j < fv.Len(); j++ {
+				if err := setDefaultsNested(fv.Index(j), fmt.Sprintf("%s[%d]", fpath, j)); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// decodeDefaults sets defaults to struct pointed by v (see SetDefaults), calls decode, and then sets defaults to
+// slice elements and pointers to struct newly created by decode. So zero values given explicitly by decode are kept,
+// except for fields in the newly created elements.
+func decodeDefaults(v interface{}, decode func() error) error {
+	rv, ok := structValue(v)
+	if !ok {
+		return decode()
+	}
+	if err := setDefaults(rv, ""); err != nil {
+		return err
+	}
+	old := deepCopy(rv)
+	if err := decode(); err != nil {
+		return err
+	}
+	return setDefaultsAdded(rv, old, "")
+}
+
+// setDefaultsAdded sets defaults to nested structs in rv which are not in old (rv before decoding)
+func setDefaultsAdded(rv, old reflect.Value, path string) error {
+	errs := []error{}
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, ok := fieldKey(f)
+		if !ok {
+			continue
+		}
+		if _, ok := f.Tag.Lookup("default"); ok {
+			continue // set before decoding
+		}
+		fpath := joinKey(path, key)
+		fv, ov := rv.Field(i), old.Field(i)
+		switch {
+		case isNested(f.Type):
+			errs = append(errs, setDefaultsAddedNested(fv, ov, fpath))
+		case f.Type.Kind() == reflect.Slice && isNested(f.Type.Elem()):
+			for j := 0; j < fv.Len(); j++ {
+				epath := fmt.Sprintf("%s[%d]", fpath, j)
+				if j >= ov.Len() {
+					errs = append(errs, setDefaultsNested(fv.Index(j), epath))
+				} else {
+					errs = append(errs, setDefaultsAddedNested(fv.Index(j), ov.Index(j), epath))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// setDefaultsAddedNested sets defaults to nested struct v, entirely if it is created by decoding (old is nil pointer)
+func setDefaultsAddedNested(v, old reflect.Value, path string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		if old.IsNil() {
+			return setDefaults(v.Elem(), path)
+		}
+		v, old = v.Elem(), old.Elem()
+	}
+	return setDefaultsAdded(v, old, path)
+}
+
+func setDefaultsNested(v reflect.Value, path string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return setDefaults(v, path)
+}
+
+// Validate validates struct pointed by v with `validate:"..."` tags, and returns *ValidationError listing every failing field.
+// Rules are separated by comma:
+//
+//	required       value is not zero
+//	omitempty      skip the following rules if value is zero
+//	min=N, max=N   number is in range, or length of string, slice or map is in range (N is duration for time.Duration)
+//	oneof=A B C    value is one of space-separated list
+//	file-exists    file exists at non-empty path
+//	regex=RE       string matches regular expression (must be the last rule, RE may contain comma)
+func Validate(v interface{}) error {
+	rv, ok := structValue(v)
+	if !ok {
+		return nil
+	}
+	errs := validateStruct(rv, "", nil)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func validateStruct(rv reflect.Value, path string, errs []*FieldError) []*FieldError {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, ok := fieldKey(f)
+		if !ok {
+			continue
+		}
+		fpath := joinKey(path, key)
+		fv := rv.Field(i)
+		for _, rule := range parseRules(f.Tag.Get("validate")) {
+			if rule.name == "omitempty" {
+				if fv.IsZero() {
+					break
+				}
+				continue
+			}
+			if msg := checkRule(fv, rule); len(msg) > 0 {
+				errs = append(errs, &FieldError{Path: fpath, Rule: rule.name, Message: msg})
+			}
+		}
+		errs = validateValue(fv, fpath, errs)
+	}
+	return errs
+}
+
+// validateValue validates nested structs in v
+func validateValue(v reflect.Value, path string, errs []*FieldError) []*FieldError {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return errs
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		return validateStruct(v, path, errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			errs = validateValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			errs = validateValue(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()), errs)
+		}
+	}
+	return errs
+}
+
+type rule struct {
+	name  string
+	param string
+}
+
+func parseRules(tag string) []rule {
+	rules := []rule{}
+	for len(tag) > 0 {
+		var item string
+		if strings.HasPrefix(tag, "regex=") {
+			item, tag = tag, ""
+		} else {
+			item, tag, _ = strings.Cut(tag, ",")
+		}
+		name, param, _ := strings.Cut(strings.TrimSpace(item), "=")
+		if len(name) > 0 {
+			rules = append(rules, rule{name: name, param: param})
+		}
+	}
+	return rules
+}
+
+// checkRule returns message if v breaks rule
+func checkRule(v reflect.Value, r rule) string {
+	if r.name == "required" {
+		if v.IsZero() {
+			return "is required"
+		}
+		return ""
+	}
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "" // only "required" is checked for nil pointer
+		}
+		v = v.Elem()
+	}
+	str, display := "", "" // display is redacted for Secret
+	if v.Kind() == reflect.String {
+		str, display = v.String(), v.String()
+		if v.Type() == secretType {
+			display = Secret(str).String()
+		}
+	}
+	switch r.name {
+	case "min", "max":
+		return checkRange(v, r)
+	case "oneof":
+		if v.Kind() != reflect.String {
+			str = fmt.Sprint(v.Interface())
+			display = str
+		}
+		for _, opt := range strings.Fields(r.param) {
+			if str == opt {
+				return ""
+			}
+		}
+		return fmt.Sprintf("%q is not one of [%s]", display, r.param)
+	case "regex":
+		re, err := regexp.Compile(r.param)
+		if err != nil {
+			return fmt.Sprintf("invalid rule %q: %v", r.param, err)
+		}
+		if v.Kind() != reflect.String {
+			return fmt.Sprintf("rule %s is not applicable to %v", r.name, v.Type())
+		}
+		if !re.MatchString(str) {
+			return fmt.Sprintf("%q does not match %q", display, r.param)
+		}
+	case "file-exists":
+		if v.Kind() != reflect.String {
+			return fmt.Sprintf("rule %s is not applicable to %v", r.name, v.Type())
+		}
+		if len(str) > 0 {
+			if _, err := os.Stat(str); err != nil {
+				return fmt.Sprintf("file %q does not exist", display)
+			}
+		}
+	default:
+		return fmt.Sprintf("unknown rule %q", r.name)
+	}
+	return ""
+}
+
+func checkRange(v reflect.Value, r rule) string {
+	var value, limit float64
+	var err error
+	what, display := "value", v.Interface()
+	switch {
+	case v.Type() == durationType:
+		var d time.Duration
+		d, err = time.ParseDuration(r.param)
+		value, limit = float64(v.Int()), float64(d)
+	case v.CanInt():
+		value = float64(v.Int())
+		limit, err = strconv.ParseFloat(r.param, 64)
+	case v.CanUint():
+		value = float64(v.Uint())
+		limit, err = strconv.ParseFloat(r.param, 64)
+	case v.CanFloat():
+		value = v.Float()
+		limit, err = strconv.ParseFloat(r.param, 64)
+	case v.Kind() == reflect.String:
+		value = float64(utf8.RuneCountInString(v.String()))
+		what, display = "length", value
+		limit, err = strconv.ParseFloat(r.param, 64)
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array || v.Kind() == reflect.Map:
+		value = float64(v.Len())
+		what, display = "length", value
+		limit, err = strconv.ParseFloat(r.param, 64)
+	default:
+		return fmt.Sprintf("rule %s is not applicable to %v", r.name, v.Type())
+	}
+	if err != nil {
+		return fmt.Sprintf("invalid rule %s=%s", r.name, r.param)
+	}
+	if r.name == "min" && value < limit {
+		return fmt.Sprintf("%s %v is less than %s", what, display, r.param)
+	}
+	if r.name == "max" && value > limit {
+		return fmt.Sprintf("%s %v is greater than %s", what, display, r.param)
+	}
+	return ""
+}
+
+func structValue(v interface{}) (reflect.Value, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	return rv.Elem(), true
+}
@@ -0,0 +1,163 @@
+package config_test
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goark/gocli/config"
+)
+
+type serverConfig struct {
+	Host string `json:"host" validate:"required"`
+	Port int    `json:"port" default:"80" validate:"min=1,max=65535"`
+}
+
+type validConfig struct {
+	Name     string         `json:"name" validate:"required,max=8"`
+	Mode     string         `json:"mode" default:"dev" validate:"oneof=dev prod"`
+	ID       string         `json:"id" validate:"omitempty,regex=^[a-z]{2,3}-[0-9]+$"`
+	CertFile string         `json:"cert_file" validate:"file-exists"`
+	Timeout  time.Duration  `json:"timeout" default:"30s" validate:"min=1s,max=1m"`
+	Tags     []string       `json:"tags" default:"a,b" validate:"min=1"`
+	Server   serverConfig   `json:"server"`
+	Mirrors  []serverConfig `json:"mirrors"`
+	Proxy    *serverConfig  `json:"proxy"`
+}
+
+func TestSetDefaults(t *testing.T) {
+	cfg := validConfig{Mode: "prod"}
+	if err := config.SetDefaults(&cfg); err != nil {
+		t.Fatalf("config.SetDefaults() is \"%v\", want nil error.", err)
+	}
+	if cfg.Mode != "prod" || cfg.Timeout != 30*time.Second || !reflect.DeepEqual(cfg.Tags, []string{"a", "b"}) || cfg.Server.Port != 80 || cfg.Proxy != nil {
+		t.Errorf("config.SetDefaults() = %+v.", cfg)
+	}
+
+	var bad struct {
+		Port int `default:"eighty"`
+	}
+	if err := config.SetDefaults(&bad); err == nil {
+		t.Error("config.SetDefaults() is nil, want error.")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := validConfig{
+		Name:     "too-long-name",
+		Mode:     "test",
+		ID:       "abc-x",
+		CertFile: filepath.Join(t.TempDir(), "none.pem"),
+		Timeout:  2 * time.Minute,
+		Tags:     []string{},
+		Server:   serverConfig{Port: 70000},
+		Mirrors:  []serverConfig{{Host: "a.example.com", Port: 80}, {Port: 0}},
+		Proxy:    &serverConfig{Host: "proxy", Port: 8080},
+	}
+	err := config.Validate(&cfg)
+	var ve *config.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("config.Validate() is \"%v\", want *config.ValidationError.", err)
+	}
+	paths := []string{}
+	for _, fe := range ve.Errors {
+		paths = append(paths, fe.Path+":"+fe.Rule)
+	}
+	want := []string{
+		"name:max",
+		"mode:oneof",
+		"id:regex",
+		"cert_file:file-exists",
+		"timeout:max",
+		"tags:min",
+		"server.host:required",
+		"server.port:max",
+		"mirrors[1].host:required",
+		"mirrors[1].port:min",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("config.Validate() errors = %v, want %v.", paths, want)
+	}
+}
+
+func TestLoadFileValidate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"name": "foo", "server": {"host": "localhost"}}`)
+
+	var cfg validConfig
+	if err := config.LoadFile(path, &cfg); err != nil {
+		t.Fatalf("config.LoadFile() is \"%v\", want nil error.", err)
+	}
+	if cfg.Mode != "dev" || cfg.Server.Port != 80 {
+		t.Errorf("config.LoadFile() = %+v.", cfg)
+	}
+
+	writeFile(t, path, `{"name": "foo", "server": {"host": "localhost"}, "mirrors": [{"host": "m"}], "proxy": {"host": "p"}}`)
+	cfg = validConfig{}
+	if err := config.LoadFile(path, &cfg); err != nil {
+		t.Fatalf("config.LoadFile() is \"%v\", want nil error.", err)
+	}
+	if len(cfg.Mirrors) != 1 || cfg.Mirrors[0].Port != 80 || cfg.Proxy == nil || cfg.Proxy.Port != 80 {
+		t.Errorf("config.LoadFile() = %+v, want defaults in slice and pointer elements.", cfg)
+	}
+
+	writeFile(t, path, `{"name": "foo", "mirrors": [{"host": "m", "port": 0}]}`)
+	cfg = validConfig{Mirrors: []serverConfig{{}}}
+	if err := config.LoadFile(path, &cfg); err == nil || cfg.Mirrors[0].Port != 0 {
+		t.Errorf("config.LoadFile() = %+v (%v), want explicit zero port in existing element.", cfg, err)
+	}
+
+	writeFile(t, path, `{"name": "foo", "mode": "debug"}`)
+	cfg = validConfig{}
+	var ve *config.ValidationError
+	if err := config.LoadFile(path, &cfg); !errors.As(err, &ve) || len(ve.Errors) != 2 {
+		t.Errorf("config.LoadFile() is \"%v\", want 2 validation errors.", err)
+	}
+}
+
+func TestDefaultsExplicitZero(t *testing.T) {
+	type zeroConfig struct {
+		Enabled bool `json:"enabled" default:"true"`
+		Retries int  `json:"retries" default:"3"`
+		Limit   int  `json:"limit" default:"5"`
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"enabled": false, "retries": 0}`)
+	want := zeroConfig{Enabled: false, Retries: 0, Limit: 5}
+
+	var cfg zeroConfig
+	if err := config.LoadFile(path, &cfg); err != nil || cfg != want {
+		t.Errorf("config.LoadFile() = %+v (%v), want %+v.", cfg, err, want)
+	}
+
+	l := config.NewLayers()
+	if err := l.AddFile(path); err != nil {
+		t.Fatalf("Layers.AddFile() is \"%v\", want nil error.", err)
+	}
+	cfg = zeroConfig{}
+	if err := l.Decode(&cfg); err != nil || cfg != want {
+		t.Errorf("Layers.Decode() = %+v (%v), want %+v.", cfg, err, want)
+	}
+}
+
+func TestValidateSecret(t *testing.T) {
+	type secretConfig struct {
+		Token config.Secret `json:"token" validate:"regex=^tok-"`
+		Level config.Secret `json:"level" validate:"oneof=low high"`
+	}
+	if err := config.Validate(&secretConfig{Token: "tok-1234", Level: "high"}); err != nil {
+		t.Errorf("config.Validate() is \"%v\", want nil error.", err)
+	}
+
+	err := config.Validate(&secretConfig{Token: "sekrit", Level: "medium"})
+	var ve *config.ValidationError
+	if !errors.As(err, &ve) || len(ve.Errors) != 2 {
+		t.Fatalf("config.Validate() is \"%v\", want 2 validation errors.", err)
+	}
+	if msg := err.Error(); strings.Contains(msg, "sekrit") || strings.Contains(msg, "medium") {
+		t.Errorf("config.Validate() is \"%v\", leaks secret.", err)
+	}
+}
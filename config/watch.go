@@ -0,0 +1,116 @@
+package config
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Op is kind of change of configuration file
+type Op int
+
+// Kinds of change of configuration file
+const (
+	Modified Op = iota + 1
+	Removed
+	Recreated
+)
+
+var opMap = map[Op]string{
+	Modified:  "modified",
+	Removed:   "removed",
+	Recreated: "recreated",
+}
+
+// String is Stringer method
+func (op Op) String() string {
+	if str, ok := opMap[op]; ok {
+		return str
+	}
+	return "unknown"
+}
+
+// Event is change event of configuration file
+type Event struct {
+	Op      Op
+	Path    string
+	ModTime time.Time // zero if removed
+}
+
+// DefaultWatchInterval is polling interval of Watch if interval is not positive
+const DefaultWatchInterval = time.Second
+
+// Watch polls configuration file at path every interval, and sends change events to the returned channel.
+// A change is reported after the file stays unchanged for one more interval, so bursts of writes by editors
+// (including save by writing temporary file and renaming it) are reported as one event.
+// The channel is closed when ctx is cancelled (e.g. by signal.Context).
+func Watch(ctx context.Context, path string, interval time.Duration) <-chan Event {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	ch := make(chan Event)
+	last := statFile(path) // before returning, so changes just after Watch are reported
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var pending *fileState
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			cur := statFile(path)
+			if cur.equal(last) { // no change (or reverted within burst)
+				pending = nil
+				continue
+			}
+			if pending == nil || !cur.equal(*pending) { // still changing
+				pending = &cur
+				continue
+			}
+			ev := Event{Op: Modified, Path: path, ModTime: cur.modTime()}
+			switch {
+			case last.info != nil && cur.info == nil:
+				ev.Op = Removed
+			case last.info == nil && cur.info != nil:
+				ev.Op = Recreated
+			}
+			last, pending = cur, nil
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- ev:
+			}
+		}
+	}()
+	return ch
+}
+
+type fileState struct {
+	info os.FileInfo // nil if not exists
+}
+
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{info: info}
+}
+
+func (s fileState) equal(t fileState) bool {
+	if s.info == nil || t.info == nil {
+		return s.info == nil && t.info == nil
+	}
+	return s.info.ModTime().Equal(t.info.ModTime()) && s.info.Size() == t.info.Size() && os.SameFile(s.info, t.info)
+}
+
+func (s fileState) modTime() time.Time {
+	if s.info == nil {
+		return time.Time{}
+	}
+	return s.info.ModTime()
+}
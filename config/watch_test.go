@@ -0,0 +1,88 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goark/gocli/config"
+)
+
+func nextEvent(t *testing.T, ch <-chan config.Event) config.Event {
+	t.Helper()
+	select {
+	case ev, ok := <-ch:
+		if !ok {
+			t.Fatal("channel of config.Watch() is closed, want event.")
+		}
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("config.Watch() sends no event, want event.")
+	}
+	return config.Event{}
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	writeFile(t, path, "{}")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := config.Watch(ctx, path, 10*time.Millisecond)
+
+	writeFile(t, path, `{"name": "foo"}`)
+	if ev := nextEvent(t, ch); ev.Op != config.Modified || ev.Path != path {
+		t.Errorf("config.Watch() event = %+v, want %v.", ev, config.Modified)
+	}
+
+	tmp := filepath.Join(dir, "config.json.tmp")
+	writeFile(t, tmp, `{"name": "bar"}`)
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	if ev := nextEvent(t, ch); ev.Op != config.Modified {
+		t.Errorf("config.Watch() event = %+v, want %v.", ev, config.Modified)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if ev := nextEvent(t, ch); ev.Op != config.Removed || !ev.ModTime.IsZero() {
+		t.Errorf("config.Watch() event = %+v, want %v.", ev, config.Removed)
+	}
+
+	writeFile(t, path, "{}")
+	if ev := nextEvent(t, ch); ev.Op != config.Recreated {
+		t.Errorf("config.Watch() event = %+v, want %v.", ev, config.Recreated)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("config.Watch() sends event after cancel, want closed channel.")
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("channel of config.Watch() is not closed after cancel.")
+	}
+}
+
+func TestOpString(t *testing.T) {
+	testCases := []struct {
+		op  config.Op
+		str string
+	}{
+		{op: config.Modified, str: "modified"},
+		{op: config.Removed, str: "removed"},
+		{op: config.Recreated, str: "recreated"},
+		{op: config.Op(0), str: "unknown"},
+	}
+	for _, tc := range testCases {
+		if str := tc.op.String(); str != tc.str {
+			t.Errorf("Op.String() = \"%v\", want \"%v\".", str, tc.str)
+		}
+	}
+}
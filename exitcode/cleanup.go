@@ -0,0 +1,158 @@
+package exitcode
+
+import (
+	"context"
+	"os"
+	signl "os/signal"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultCleanupTimeout is default timeout of cleanup hook
+const DefaultCleanupTimeout = 5 * time.Second
+
+// cleanup is cleanup hook registered by RegisterCleanup
+type cleanup struct {
+	id       uint64
+	fn       func(context.Context)
+	priority int
+	timeout  time.Duration
+}
+
+// CleanupOptFunc is self-referential function for functional options pattern (RegisterCleanup function)
+type CleanupOptFunc func(*cleanup)
+
+// WithPriority returns function for setting priority of cleanup hook (hooks of higher priority run first; 0 by default)
+func WithPriority(priority int) CleanupOptFunc {
+	return func(c *cleanup) {
+		c.priority = priority
+	}
+}
+
+// WithTimeout returns function for setting timeout of cleanup hook (DefaultCleanupTimeout if not positive).
+// Hook running over the timeout is abandoned, and the next hook runs.
+func WithTimeout(timeout time.Duration) CleanupOptFunc {
+	return func(c *cleanup) {
+		if timeout > 0 {
+			c.timeout = timeout
+		}
+	}
+}
+
+var (
+	cleanupMutex   sync.Mutex
+	cleanups       = []*cleanup{}
+	cleanupID      uint64
+	cleanupSignals = []os.Signal{os.Interrupt}
+)
+
+// RegisterCleanup registers cleanup hook run by Exit (or RunCleanups), and returns function to unregister it.
+// Hooks run in order of priority, and in LIFO order among the same priority (like defer statements).
+// Context passed to hook is canceled at its timeout.
+func RegisterCleanup(fn func(ctx context.Context), opts ...CleanupOptFunc) func() {
+	if fn == nil {
+		return func() {}
+	}
+	cleanupMutex.Lock()
+	defer cleanupMutex.Unlock()
+	cleanupID++
+	c := &cleanup{id: cleanupID, fn: fn, timeout: DefaultCleanupTimeout}
+	for _, opt := range opts {
+		opt(c)
+	}
+	cleanups = append(cleanups, c)
+	return func() {
+		cleanupMutex.Lock()
+		defer cleanupMutex.Unlock()
+		for i, r := range cleanups {
+			if r.id == c.id {
+				cleanups = append(cleanups[:i], cleanups[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// SetCleanupSignals sets signals which terminate the process immediately while cleanup hooks are running
+// (e.g. second Ctrl+C after the first one started graceful shutdown). os.Interrupt by default.
+func SetCleanupSignals(sig ...os.Signal) {
+	cleanupMutex.Lock()
+	defer cleanupMutex.Unlock()
+	cleanupSignals = sig
+}
+
+// RunCleanups runs registered cleanup hooks, and unregisters them.
+// It is called by Exit and ExitIfNotNormal, so call it only when the process ends without them.
+// If a signal set by SetCleanupSignals arrives while hooks are running, the process exits immediately
+// (the exiter set by SetExiter is called in the goroutine calling RunCleanups, and remaining hooks are abandoned).
+func RunCleanups() {
+	if code, interrupted := runCleanups(); interrupted {
+		exitNow(code)
+	}
+}
+
+// runCleanups runs registered cleanup hooks, and returns exit code if they are interrupted by signal
+func runCleanups() (ExitCode, bool) {
+	cleanupMutex.Lock()
+	hooks := cleanups
+	cleanups = []*cleanup{}
+	sigs := cleanupSignals
+	cleanupMutex.Unlock()
+	if len(hooks) == 0 {
+		return Normal, false
+	}
+	sort.SliceStable(hooks, func(i, j int) bool {
+		if hooks[i].priority != hooks[j].priority {
+			return hooks[i].priority > hooks[j].priority
+		}
+		return hooks[i].id > hooks[j].id // LIFO
+	})
+
+	var sigCh chan os.Signal // nil channel (never receives) if no signal is set
+	if len(sigs) > 0 {
+		sigCh = make(chan os.Signal, 1)
+		signl.Notify(sigCh, sigs...)
+		defer signl.Stop(sigCh)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, c := range hooks {
+			c.run()
+		}
+	}()
+	select {
+	case <-done:
+		return Normal, false
+	case sig := <-sigCh: // exit immediately
+		return signalExitCode(sig), true
+	}
+}
+
+// run runs cleanup hook, giving up at its timeout
+func (c *cleanup) run() {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() { _ = recover() }() // panic in hook must not prevent exit
+		c.fn(ctx)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done(): // hook hangs
+	}
+}
+
+// signalExitCode returns exit code for termination by sig (128+signal number, e.g. 143 for SIGTERM)
+func signalExitCode(sig os.Signal) ExitCode {
+	if n, ok := signalNumber(sig); ok {
+		return Signaled(n)
+	}
+	if sig == os.Interrupt {
+		return Interrupted
+	}
+	return Abnormal
+}
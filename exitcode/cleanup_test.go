@@ -0,0 +1,50 @@
+package exitcode
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunCleanups(t *testing.T) {
+	var mutex sync.Mutex
+	order := []string{}
+	hook := func(name string) func(context.Context) {
+		return func(context.Context) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			order = append(order, name)
+		}
+	}
+	RegisterCleanup(hook("first"))
+	RegisterCleanup(hook("second"))
+	RegisterCleanup(hook("high"), WithPriority(10))
+	RegisterCleanup(hook("low"), WithPriority(-10))
+	unregister := RegisterCleanup(hook("unregistered"))
+	RegisterCleanup(func(context.Context) { panic("in cleanup") })
+	RegisterCleanup(func(ctx context.Context) { // hangs until timeout
+		<-ctx.Done()
+		select {}
+	}, WithTimeout(10*time.Millisecond))
+	unregister()
+
+	start := time.Now()
+	RunCleanups()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("RunCleanups() takes %v, want hanging hook abandoned.", elapsed)
+	}
+	want := []string{"high", "second", "first", "low"}
+	mutex.Lock()
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("order of cleanup hooks = %v, want %v.", order, want)
+	}
+	order = []string{}
+	mutex.Unlock()
+
+	RunCleanups() // hooks run only once
+	if len(order) != 0 {
+		t.Errorf("cleanup hooks run again: %v", order)
+	}
+}
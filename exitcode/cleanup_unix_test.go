@@ -0,0 +1,49 @@
+//go:build unix
+
+package exitcode
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRunCleanupsSignal(t *testing.T) {
+	var codes []ExitCode
+	restore := SetExiter(func(c ExitCode) { codes = append(codes, c) }) // returns unlike os.Exit
+	defer restore()
+	defer SetCleanupSignals(os.Interrupt)
+
+	testCases := []struct {
+		sig  syscall.Signal
+		code ExitCode
+	}{
+		{sig: syscall.SIGINT, code: Interrupted},
+		{sig: syscall.SIGTERM, code: 143},
+	}
+	for _, tc := range testCases {
+		SetCleanupSignals(tc.sig)
+		codes = nil
+		started := make(chan struct{})
+		RegisterCleanup(func(ctx context.Context) { // blocks until the second signal
+			close(started)
+			<-ctx.Done()
+		}, WithTimeout(5*time.Second))
+		go func(sig syscall.Signal) {
+			<-started
+			_ = syscall.Kill(os.Getpid(), sig)
+		}(tc.sig)
+
+		start := time.Now()
+		Normal.Exit()
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("Exit() by %v takes %v, want immediate exit.", tc.sig, elapsed)
+		}
+		if want := []ExitCode{tc.code}; !reflect.DeepEqual(codes, want) {
+			t.Errorf("exit codes by %v = %v, want %v.", tc.sig, codes, want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package exitcode
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/goark/gocli/rwi"
+)
+
+// Error is error carrying exit code and optional user-facing hint.
+// Library code returns it to decide exit status without calling os.Exit.
+type Error struct {
+	Err  error
+	Code ExitCode
+	Hint string // e.g. "see 'app --help'"
+}
+
+var _ ExitCoder = (*Error)(nil)
+
+// NewError returns a new Error instance wrapping err (nil if err is nil)
+func NewError(err error, code ExitCode, hint string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Err: err, Code: code, Hint: hint}
+}
+
+// Error implements error interface (message of wrapped error, or description of exit code)
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Code.String()
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns wrapped error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode implements ExitCoder interface
+func (e *Error) ExitCode() ExitCode {
+	return e.Code
+}
+
+// Render writes err in "app: error: message (hint)" format to w.
+// Hint is taken from Error in the wrapped error chain. Nothing is written if err is nil.
+func Render(w io.Writer, appName string, err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := "error: " + err.Error()
+	if len(appName) > 0 {
+		msg = appName + ": " + msg
+	}
+	var e *Error
+	if errors.As(err, &e) && len(e.Hint) > 0 {
+		msg += " (" + e.Hint + ")"
+	}
+	_, werr := fmt.Fprintln(w, msg)
+	return werr
+}
+
+// Report renders err to error writer of ui (see Render), and returns exit code for err (see FromError)
+func Report(ui *rwi.RWI, appName string, err error) ExitCode {
+	_ = Render(ui.ErrorWriter(), appName, err)
+	return FromError(err)
+}
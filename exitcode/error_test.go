@@ -0,0 +1,55 @@
+package exitcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/goark/gocli/rwi"
+)
+
+func TestError(t *testing.T) {
+	errBase := errors.New("no input files")
+	err := NewError(errBase, Usage, "see 'app --help'")
+	if !errors.Is(err, errBase) {
+		t.Errorf("errors.Is(%v, %v) = false, want true.", err, errBase)
+	}
+	if err.Error() != "no input files" {
+		t.Errorf("Error.Error() = %v, want %v.", err.Error(), "no input files")
+	}
+	if ec := FromError(fmt.Errorf("run: %w", err)); ec != Usage {
+		t.Errorf("FromError() = %v, want %v.", ec, Usage)
+	}
+	if NewError(nil, Usage, "") != nil {
+		t.Errorf("NewError(nil) is not nil.")
+	}
+	if msg := (&Error{Code: Config}).Error(); msg != "configuration error" {
+		t.Errorf("Error.Error() = %v, want %v.", msg, "configuration error")
+	}
+}
+
+func TestReport(t *testing.T) {
+	testCases := []struct { //Test case for Report
+		app string
+		err error
+		out string
+		ec  ExitCode
+	}{
+		{"app", NewError(errors.New("no input files"), Usage, "see 'app --help'"), "app: error: no input files (see 'app --help')\n", Usage},
+		{"app", fmt.Errorf("load: %w", NewError(errors.New("bad value"), Config, "")), "app: error: load: bad value\n", Config},
+		{"", errors.New("failure"), "error: failure\n", Abnormal},
+		{"app", nil, "", Normal},
+	}
+
+	for _, testCase := range testCases {
+		errBuf := &bytes.Buffer{}
+		ec := Report(rwi.New(rwi.WithErrorWriter(errBuf)), testCase.app, testCase.err)
+		if ec != testCase.ec {
+			t.Errorf("Report() = %v, want %v.", ec, testCase.ec)
+		}
+		if errBuf.String() != testCase.out {
+			t.Errorf("Report() output = %q, want %q.", errBuf.String(), testCase.out)
+		}
+	}
+}
@@ -4,7 +4,7 @@
 // http://creativecommons.org/publicdomain/zero/1.0/
 package exitcode
 
-import "os"
+import "fmt"
 
 // ExitCode is OS exit code enumeration class
 type ExitCode int
@@ -16,20 +16,89 @@ const (
 	Abnormal
 )
 
+// Exit codes of sysexits.h
+const (
+	Usage       ExitCode = iota + 64 // command line usage error
+	DataErr                          // data format error
+	NoInput                          // cannot open input
+	NoUser                           // addressee unknown
+	NoHost                           // host name unknown
+	Unavailable                      // service unavailable
+	Software                         // internal software error
+	OSErr                            // system error (e.g., can't fork)
+	OSFile                           // critical OS file missing
+	CantCreat                        // can't create (user) output file
+	IOErr                            // input/output error
+	TempFail                         // temp failure; user is invited to retry
+	Protocol                         // remote error in protocol
+	NoPerm                           // permission denied
+	Config                           // configuration error
+)
+
+// Exit codes of shell conventions
+const (
+	CannotExecute   ExitCode = 126 // command invoked cannot execute
+	CommandNotFound ExitCode = 127 // command not found
+	Interrupted     ExitCode = 130 // terminated by SIGINT (128+2)
+)
+
 var exitcodeMap = map[ExitCode]string{
-	Normal:   "normal end",
-	Abnormal: "abnormal end",
+	Normal:          "normal end",
+	Abnormal:        "abnormal end",
+	Usage:           "command line usage error",
+	DataErr:         "data format error",
+	NoInput:         "cannot open input",
+	NoUser:          "addressee unknown",
+	NoHost:          "host name unknown",
+	Unavailable:     "service unavailable",
+	Software:        "internal software error",
+	OSErr:           "system error",
+	OSFile:          "critical OS file missing",
+	CantCreat:       "cannot create output file",
+	IOErr:           "input/output error",
+	TempFail:        "temporary failure",
+	Protocol:        "remote error in protocol",
+	NoPerm:          "permission denied",
+	Config:          "configuration error",
+	CannotExecute:   "command cannot execute",
+	CommandNotFound: "command not found",
+	Interrupted:     "interrupted",
 }
 
-// Exit calls os.Exit()
+// exit code 128+n for termination by signal n
+const (
+	signalBase ExitCode = 128
+	maxSignal           = 127
+)
+
+// Signaled returns exit code for process terminated by signal number signum (128+signum)
+func Signaled(signum int) ExitCode {
+	if signum <= 0 || signum > maxSignal {
+		return Abnormal
+	}
+	return signalBase + ExitCode(signum)
+}
+
+// Signal returns signal number if exit code is 128+n
+func (c ExitCode) Signal() (int, bool) {
+	if c <= signalBase || c > signalBase+maxSignal {
+		return 0, false
+	}
+	return int(c - signalBase), true
+}
+
+// Exit runs cleanup hooks (see RegisterCleanup), and calls os.Exit()
 func (c ExitCode) Exit() {
-	os.Exit(int(c))
+	if code, interrupted := runCleanups(); interrupted {
+		c = code
+	}
+	exitNow(c)
 }
 
-// ExitIfNotNormal calls os.Exit() if exit code is not Normal.
+// ExitIfNotNormal runs cleanup hooks and calls os.Exit() if exit code is not Normal.
 func (c ExitCode) ExitIfNotNormal() {
 	if c != Normal {
-		os.Exit(int(c))
+		c.Exit()
 	}
 }
 
@@ -38,5 +107,8 @@ func (c ExitCode) String() string {
 	if str, ok := exitcodeMap[c]; ok {
 		return str
 	}
+	if signum, ok := c.Signal(); ok {
+		return fmt.Sprintf("terminated by signal %d", signum)
+	}
 	return "unknown"
 }
@@ -18,3 +18,56 @@ func TestExitCode(t *testing.T) {
 		}
 	}
 }
+
+func TestSysexits(t *testing.T) {
+	testCases := []struct {
+		ec   ExitCode
+		code int
+		str  string
+	}{
+		{Usage, 64, "command line usage error"},
+		{DataErr, 65, "data format error"},
+		{NoInput, 66, "cannot open input"},
+		{Unavailable, 69, "service unavailable"},
+		{Software, 70, "internal software error"},
+		{IOErr, 74, "input/output error"},
+		{TempFail, 75, "temporary failure"},
+		{NoPerm, 77, "permission denied"},
+		{Config, 78, "configuration error"},
+		{CannotExecute, 126, "command cannot execute"},
+		{CommandNotFound, 127, "command not found"},
+		{Interrupted, 130, "interrupted"},
+		{Signaled(15), 143, "terminated by signal 15"},
+		{Signaled(0), 1, "abnormal end"},
+		{ExitCode(79), 79, "unknown"},
+	}
+
+	for _, testCase := range testCases {
+		if int(testCase.ec) != testCase.code {
+			t.Errorf("ExitCode = %d, want %d.", int(testCase.ec), testCase.code)
+		}
+		if testCase.ec.String() != testCase.str {
+			t.Errorf("ExitCode(%d).String() = %v, want %v.", int(testCase.ec), testCase.ec.String(), testCase.str)
+		}
+	}
+}
+
+func TestSignal(t *testing.T) {
+	testCases := []struct {
+		ec     ExitCode
+		signum int
+		ok     bool
+	}{
+		{Interrupted, 2, true},
+		{ExitCode(137), 9, true},
+		{ExitCode(128), 0, false},
+		{Usage, 0, false},
+		{ExitCode(256), 0, false},
+	}
+
+	for _, testCase := range testCases {
+		if signum, ok := testCase.ec.Signal(); signum != testCase.signum || ok != testCase.ok {
+			t.Errorf("ExitCode(%d).Signal() = %v, %v, want %v, %v.", int(testCase.ec), signum, ok, testCase.signum, testCase.ok)
+		}
+	}
+}
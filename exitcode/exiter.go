@@ -0,0 +1,37 @@
+package exitcode
+
+import (
+	"os"
+	"sync"
+)
+
+var (
+	exiterMutex sync.RWMutex
+	exiter      = func(c ExitCode) { os.Exit(int(c)) }
+)
+
+// SetExiter replaces function terminating the process in Exit and ExitIfNotNormal (os.Exit by default),
+// and returns function to restore the previous one. Tests use it to capture exit code (see exittest package).
+// The exiter must not return, e.g. it panics to unwind the caller; if it returns, Exit returns too.
+func SetExiter(exit func(ExitCode)) (restore func()) {
+	exiterMutex.Lock()
+	defer exiterMutex.Unlock()
+	prev := exiter
+	if exit == nil {
+		exit = func(c ExitCode) { os.Exit(int(c)) }
+	}
+	exiter = exit
+	return func() {
+		exiterMutex.Lock()
+		defer exiterMutex.Unlock()
+		exiter = prev
+	}
+}
+
+// exitNow terminates the process by exiter without cleanup
+func exitNow(c ExitCode) {
+	exiterMutex.RLock()
+	exit := exiter
+	exiterMutex.RUnlock()
+	exit(c)
+}
@@ -0,0 +1,25 @@
+package exitcode
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSetExiter(t *testing.T) {
+	codes := []ExitCode{}
+	restore := SetExiter(func(c ExitCode) { codes = append(codes, c) })
+	defer restore()
+
+	cleaned := false
+	RegisterCleanup(func(context.Context) { cleaned = true })
+	Usage.Exit()
+	if !cleaned {
+		t.Errorf("ExitCode.Exit() does not run cleanup hooks.")
+	}
+	Normal.ExitIfNotNormal()
+	NoInput.ExitIfNotNormal()
+
+	if len(codes) != 2 || codes[0] != Usage || codes[1] != NoInput {
+		t.Errorf("exit codes = %v, want [%v %v].", codes, Usage, NoInput)
+	}
+}
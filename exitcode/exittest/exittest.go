@@ -0,0 +1,61 @@
+// Package exittest : Test helpers for code paths calling exitcode.ExitCode.Exit
+//
+// These codes are licensed under CC0.
+// http://creativecommons.org/publicdomain/zero/1.0/
+package exittest
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/goark/gocli/exitcode"
+)
+
+// exitPanic is value of panic unwinding function called Exit
+type exitPanic struct {
+	code exitcode.ExitCode
+}
+
+// mutex serializes Capture, because exiter is shared in the process
+var mutex sync.Mutex
+
+// Capture runs fn, and returns exit code if fn calls ExitCode.Exit (or ExitIfNotNormal) instead of terminating the process.
+// exited is false if fn returns without exit. Exit must be called in the goroutine running fn.
+func Capture(fn func()) (code exitcode.ExitCode, exited bool) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	restore := exitcode.SetExiter(func(c exitcode.ExitCode) { panic(exitPanic{code: c}) })
+	defer restore()
+	defer func() {
+		if r := recover(); r != nil {
+			p, ok := r.(exitPanic)
+			if !ok {
+				panic(r)
+			}
+			code, exited = p.code, true
+		}
+	}()
+	fn()
+	return exitcode.Normal, false
+}
+
+// AssertExit reports failure to t unless fn would exit with code want
+func AssertExit(t testing.TB, want exitcode.ExitCode, fn func()) {
+	t.Helper()
+	code, exited := Capture(fn)
+	if !exited {
+		t.Errorf("function returns without exit, want exit with %d (%v).", int(want), want)
+		return
+	}
+	if code != want {
+		t.Errorf("function exits with %d (%v), want %d (%v).", int(code), code, int(want), want)
+	}
+}
+
+// AssertNoExit reports failure to t if fn would exit
+func AssertNoExit(t testing.TB, fn func()) {
+	t.Helper()
+	if code, exited := Capture(fn); exited {
+		t.Errorf("function exits with %d (%v), want no exit.", int(code), code)
+	}
+}
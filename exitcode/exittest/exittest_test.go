@@ -0,0 +1,64 @@
+package exittest_test
+
+import (
+	"testing"
+
+	"github.com/goark/gocli/exitcode"
+	"github.com/goark/gocli/exitcode/exittest"
+)
+
+func run(args []string) {
+	if len(args) == 0 {
+		exitcode.Usage.Exit()
+	}
+	exitcode.Normal.ExitIfNotNormal()
+}
+
+func TestCapture(t *testing.T) {
+	testCases := []struct {
+		args   []string
+		code   exitcode.ExitCode
+		exited bool
+	}{
+		{args: nil, code: exitcode.Usage, exited: true},
+		{args: []string{"file"}, code: exitcode.Normal, exited: false},
+	}
+	for _, tc := range testCases {
+		code, exited := exittest.Capture(func() { run(tc.args) })
+		if code != tc.code || exited != tc.exited {
+			t.Errorf("Capture() = %v, %v, want %v, %v.", code, exited, tc.code, tc.exited)
+		}
+	}
+}
+
+// recorder is testing.TB recording failure
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(string, ...interface{}) {
+	r.failed = true
+}
+
+func TestAssertExit(t *testing.T) {
+	exittest.AssertExit(t, exitcode.Usage, func() { run(nil) })
+	exittest.AssertNoExit(t, func() { run([]string{"file"}) })
+
+	mock := &recorder{TB: t}
+	exittest.AssertExit(mock, exitcode.Config, func() { run(nil) })
+	if !mock.failed {
+		t.Errorf("AssertExit() with other exit code does not fail.")
+	}
+}
+
+func TestCapturePanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("recover() = %v, want \"boom\".", r)
+		}
+	}()
+	exittest.Capture(func() { panic("boom") })
+}
@@ -0,0 +1,27 @@
+//go:build unix
+
+package exittest_test
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/goark/gocli/exitcode"
+	"github.com/goark/gocli/exitcode/exittest"
+)
+
+func TestCaptureSignalInCleanup(t *testing.T) {
+	started := make(chan struct{})
+	exitcode.RegisterCleanup(func(ctx context.Context) { // graceful shutdown interrupted by second Ctrl+C
+		close(started)
+		<-ctx.Done()
+	}, exitcode.WithTimeout(5*time.Second))
+	go func() {
+		<-started
+		_ = syscall.Kill(os.Getpid(), syscall.SIGINT)
+	}()
+	exittest.AssertExit(t, exitcode.Interrupted, func() { exitcode.Normal.Exit() })
+}
@@ -0,0 +1,79 @@
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+)
+
+// ExitCoder is interface for errors supplying their own exit code
+type ExitCoder interface {
+	ExitCode() ExitCode
+}
+
+// Rule is rule mapping error to exit code; it reports false if the rule does not match err
+type Rule func(err error) (ExitCode, bool)
+
+var (
+	ruleMutex sync.RWMutex
+	rules     = []Rule{}
+	// builtinRules are checked after rules registered by applications
+	builtinRules = []Rule{
+		Is(context.Canceled, Interrupted),
+		Is(os.ErrPermission, NoPerm),
+		Is(os.ErrNotExist, NoInput),
+	}
+)
+
+// Is returns Rule mapping errors matching target (errors.Is) to code
+func Is(target error, code ExitCode) Rule {
+	return func(err error) (ExitCode, bool) {
+		if errors.Is(err, target) {
+			return code, true
+		}
+		return Normal, false
+	}
+}
+
+// RegisterRule registers rule for FromError. Rules registered later take precedence.
+func RegisterRule(rule Rule) {
+	if rule == nil {
+		return
+	}
+	ruleMutex.Lock()
+	defer ruleMutex.Unlock()
+	rules = append(rules, rule)
+}
+
+// Register registers rule mapping errors matching target (errors.Is) to code for FromError
+func Register(target error, code ExitCode) {
+	RegisterRule(Is(target, code))
+}
+
+// FromError returns exit code for err, walking the wrapped error chain:
+// Normal if err is nil, code of ExitCoder in the chain, registered rules,
+// and built-in rules (context.Canceled: Interrupted, os.ErrPermission: NoPerm, os.ErrNotExist: NoInput).
+// Abnormal is returned if nothing matches.
+func FromError(err error) ExitCode {
+	if err == nil {
+		return Normal
+	}
+	var coder ExitCoder
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+	ruleMutex.RLock()
+	defer ruleMutex.RUnlock()
+	for i := len(rules) - 1; i >= 0; i-- {
+		if code, ok := rules[i](err); ok {
+			return code
+		}
+	}
+	for _, rule := range builtinRules {
+		if code, ok := rule(err); ok {
+			return code
+		}
+	}
+	return Abnormal
+}
@@ -0,0 +1,57 @@
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"testing"
+)
+
+type codedError struct {
+	code ExitCode
+}
+
+func (e codedError) Error() string {
+	return "coded error"
+}
+
+func (e codedError) ExitCode() ExitCode {
+	return e.code
+}
+
+var errQuota = errors.New("quota exceeded")
+
+func TestFromError(t *testing.T) {
+	Register(errQuota, TempFail)
+	RegisterRule(func(err error) (ExitCode, bool) {
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) && pathErr.Op == "create" {
+			return CantCreat, true
+		}
+		return Normal, false
+	})
+
+	_, notExist := os.Open("not-exist.txt")
+	testCases := []struct { //Test case for FromError
+		err error
+		ec  ExitCode
+	}{
+		{nil, Normal},
+		{errors.New("error"), Abnormal},
+		{codedError{code: Usage}, Usage},
+		{fmt.Errorf("wrapped: %w", codedError{code: Config}), Config},
+		{fmt.Errorf("canceled: %w", context.Canceled), Interrupted},
+		{fmt.Errorf("open: %w", os.ErrPermission), NoPerm},
+		{notExist, NoInput},
+		{fmt.Errorf("upload: %w", errQuota), TempFail},
+		{&fs.PathError{Op: "create", Path: "out.txt", Err: os.ErrNotExist}, CantCreat}, // registered rule precedes built-in rule
+	}
+
+	for _, testCase := range testCases {
+		if ec := FromError(testCase.err); ec != testCase.ec {
+			t.Errorf("FromError(%v) = %v, want %v.", testCase.err, ec, testCase.ec)
+		}
+	}
+}
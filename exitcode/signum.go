@@ -0,0 +1,14 @@
+//go:build !plan9
+
+package exitcode
+
+import (
+	"os"
+	"syscall"
+)
+
+// signalNumber returns number of signal sig
+func signalNumber(sig os.Signal) (int, bool) {
+	s, ok := sig.(syscall.Signal)
+	return int(s), ok
+}
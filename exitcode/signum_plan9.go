@@ -0,0 +1,8 @@
+package exitcode
+
+import "os"
+
+// signalNumber returns false, because signals are notes (strings) on plan9
+func signalNumber(sig os.Signal) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,25 @@
+//go:build unix || windows
+
+package exitcode
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestSignalExitCode(t *testing.T) {
+	testCases := []struct {
+		sig  os.Signal
+		code ExitCode
+	}{
+		{sig: os.Interrupt, code: Interrupted},
+		{sig: syscall.SIGTERM, code: 143},
+		{sig: syscall.SIGHUP, code: 129},
+	}
+	for _, tc := range testCases {
+		if code := signalExitCode(tc.sig); code != tc.code {
+			t.Errorf("signalExitCode(%v) is \"%v\", want \"%v\".", tc.sig, code, tc.code)
+		}
+	}
+}
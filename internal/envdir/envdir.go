@@ -0,0 +1,40 @@
+// Package envdir : Environment variables overriding application directories (shared by appdir, config and cache packages)
+//
+// These codes are licensed under CC0.
+// http://creativecommons.org/publicdomain/zero/1.0/
+package envdir
+
+import (
+	"os"
+	"strings"
+)
+
+// Prefix returns prefix of environment variables for appName (e.g. "my-app" -> "MY_APP_", and "" for empty name)
+func Prefix(appName string) string {
+	if len(appName) == 0 {
+		return ""
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+			return r
+		}
+		return '_'
+	}, appName) + "_"
+}
+
+// Name returns name of environment variable overriding directory of kind (e.g. "MY_APP_" and "config" -> "MY_APP_CONFIG_DIR")
+func Name(prefix, kind string) string {
+	return prefix + strings.ToUpper(kind) + "_DIR"
+}
+
+// Lookup returns directory of kind for appName given by environment variable Name(Prefix(appName), kind),
+// or empty string if appName is empty or the variable is not defined.
+func Lookup(appName, kind string) string {
+	if len(appName) == 0 {
+		return ""
+	}
+	return os.Getenv(Name(Prefix(appName), kind))
+}
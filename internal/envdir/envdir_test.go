@@ -0,0 +1,30 @@
+package envdir_test
+
+import (
+	"testing"
+
+	"github.com/goark/gocli/internal/envdir"
+)
+
+func TestLookup(t *testing.T) {
+	t.Setenv("MY_APP_CONFIG_DIR", "/etc/my-app")
+	testCases := []struct {
+		appName string
+		kind    string
+		prefix  string
+		dir     string
+	}{
+		{appName: "my-app", kind: "config", prefix: "MY_APP_", dir: "/etc/my-app"},
+		{appName: "my-app", kind: "cache", prefix: "MY_APP_", dir: ""},
+		{appName: "My.App", kind: "config", prefix: "MY_APP_", dir: "/etc/my-app"},
+		{appName: "", kind: "config", prefix: "", dir: ""},
+	}
+	for _, tc := range testCases {
+		if prefix := envdir.Prefix(tc.appName); prefix != tc.prefix {
+			t.Errorf("envdir.Prefix(\"%v\") = \"%v\", want \"%v\".", tc.appName, prefix, tc.prefix)
+		}
+		if dir := envdir.Lookup(tc.appName, tc.kind); dir != tc.dir {
+			t.Errorf("envdir.Lookup(\"%v\", \"%v\") = \"%v\", want \"%v\".", tc.appName, tc.kind, dir, tc.dir)
+		}
+	}
+}